@@ -1,21 +1,27 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
-	"regexp"
 	"sync"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gizak/termui/v3"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	kr "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/ynqa/ktop/pkg/alert"
 	"github.com/ynqa/ktop/pkg/ktop"
 	"github.com/ynqa/ktop/pkg/kube"
+	"github.com/ynqa/ktop/pkg/resource"
 	"github.com/ynqa/ktop/pkg/ui"
 )
 
@@ -32,15 +38,45 @@ __  __    ______   ______     ______
 <Up>            Up
 <Down>          Down
 <Right>, <Left> Switch Table Mode
+<Enter>, <Esc>  Drill Down (Node > Pod > Container), Go Back
+<Space>         Mark Row to Compare on Graphs
+<s>             Switch Sort Column
+<S>             Reverse Sort Order
+</>             Filter (<Tab> Switch Match, <Enter> Apply, <Esc> Cancel)
+<n>             Filter Namespaces
+<a>             Switch Graph Scale (data > limit > fixed)
+<t>             Switch Trend Column (off > cpu > memory)
+<g>             Switch Graphs (usage > network > filesystem)
 `
 )
 
 type ktopCmd struct {
 	k8sFlags       *genericclioptions.ConfigFlags
 	interval       time.Duration
+	allNamespaces  bool
+	namespaceQuery string
 	nodeQuery      string
 	podQuery       string
+	selector       string
+	fieldSelector  string
+	nodeSelector   string
 	containerQuery string
+	metricsSource  string
+	prometheusURL  string
+	kubeletStats   bool
+	batch          bool
+	iterations     int
+	table          string
+	output         string
+	graphScale     string
+	cpuGraphMax    string
+	memoryGraphMax string
+	sparkline      string
+	thresholds     resource.Thresholds
+	alertRules     string
+	alertLog       string
+	alertWebhook   string
+	alertBell      bool
 	renderMutex    sync.RWMutex
 }
 
@@ -51,39 +87,191 @@ func newKtopCmd() *cobra.Command {
 		Short: "Kubernetes monitoring dashboard on terminal",
 		RunE:  ktop.run,
 	}
-	cmd.Flags().DurationVarP(
+	cmd.PersistentFlags().DurationVarP(
 		&ktop.interval,
 		"interval",
 		"i",
 		1*time.Second,
 		"set interval",
 	)
-	cmd.Flags().StringVarP(
+	cmd.PersistentFlags().BoolVarP(
+		&ktop.allNamespaces,
+		"all-namespaces",
+		"A",
+		false,
+		"watch pods in all namespaces",
+	)
+	cmd.PersistentFlags().StringVar(
+		&ktop.namespaceQuery,
+		"namespace-query",
+		".*",
+		"namespace query",
+	)
+	cmd.PersistentFlags().StringVarP(
 		&ktop.nodeQuery,
 		"node-query",
 		"N",
 		".*",
 		"node query",
 	)
-	cmd.Flags().StringVarP(
+	cmd.PersistentFlags().StringVarP(
 		&ktop.podQuery,
 		"pod-query",
 		"P",
 		".*",
 		"pod query",
 	)
-	cmd.Flags().StringVarP(
+	cmd.PersistentFlags().StringVarP(
 		&ktop.containerQuery,
 		"container-query",
 		"C",
 		".*",
 		"container query",
 	)
+	cmd.PersistentFlags().StringVarP(
+		&ktop.selector,
+		"selector",
+		"l",
+		"",
+		"label selector for pods (e.g. app=nginx)",
+	)
+	cmd.PersistentFlags().StringVar(
+		&ktop.fieldSelector,
+		"field-selector",
+		"",
+		"field selector for pods (e.g. status.phase=Running)",
+	)
+	cmd.PersistentFlags().StringVar(
+		&ktop.nodeSelector,
+		"node-selector",
+		"",
+		"label selector for nodes",
+	)
+	cmd.PersistentFlags().StringVar(
+		&ktop.metricsSource,
+		"metrics-source",
+		"metrics-server",
+		"backend to fetch metrics from (metrics-server|heapster|prometheus)",
+	)
+	cmd.PersistentFlags().StringVar(
+		&ktop.prometheusURL,
+		"prometheus-url",
+		"http://localhost:9090",
+		"url of prometheus for the prometheus metrics source",
+	)
+	cmd.PersistentFlags().BoolVar(
+		&ktop.kubeletStats,
+		"kubelet-stats",
+		false,
+		"fetch network and filesystem stats from the summary api of the kubelets",
+	)
+	cmd.Flags().BoolVarP(
+		&ktop.batch,
+		"batch",
+		"b",
+		false,
+		"print tables to stdout without the dashboard",
+	)
+	cmd.Flags().IntVar(
+		&ktop.iterations,
+		"iterations",
+		0,
+		"number of refreshes before exit in batch mode (0 means unlimited)",
+	)
+	cmd.Flags().StringVar(
+		&ktop.table,
+		"table",
+		resource.SummarizedType,
+		fmt.Sprintf("table to print in batch mode (%v|%v|%v|%v|%v)",
+			resource.SummarizedType, resource.AllType, resource.NodeType, resource.WorkloadType, resource.NamespaceType),
+	)
+	cmd.Flags().StringVarP(
+		&ktop.output,
+		"output",
+		"o",
+		"",
+		"output format in batch mode (json|jsonl|csv)",
+	)
+	cmd.Flags().StringVar(
+		&ktop.graphScale,
+		"graph-scale",
+		ui.ScaleFitData.String(),
+		fmt.Sprintf("scale of the Y-axis of graphs (%v|%v|%v)", ui.ScaleFitData, ui.ScaleFitLimit, ui.ScaleFixed),
+	)
+	cmd.Flags().StringVar(
+		&ktop.cpuGraphMax,
+		"cpu-graph-max",
+		"1",
+		"top of the cpu graph in the fixed scale",
+	)
+	cmd.Flags().StringVar(
+		&ktop.memoryGraphMax,
+		"memory-graph-max",
+		"1Gi",
+		"top of the memory graph in the fixed scale",
+	)
+	cmd.Flags().StringVar(
+		&ktop.sparkline,
+		"sparkline",
+		"off",
+		"usage drawn as the trend of each row (off|cpu|memory)",
+	)
+	cmd.Flags().Float64Var(
+		&ktop.thresholds.CPUWarning,
+		"cpu-warning",
+		80,
+		"percentage of cpu usage against the limit (the allocatable for nodes) to warn, or 0 to disable",
+	)
+	cmd.Flags().Float64Var(
+		&ktop.thresholds.CPUCritical,
+		"cpu-critical",
+		90,
+		"percentage of cpu usage against the limit (the allocatable for nodes) to alert, or 0 to disable",
+	)
+	cmd.Flags().Float64Var(
+		&ktop.thresholds.MemoryWarning,
+		"memory-warning",
+		80,
+		"percentage of memory usage against the limit (the allocatable for nodes) to warn, or 0 to disable",
+	)
+	cmd.Flags().Float64Var(
+		&ktop.thresholds.MemoryCritical,
+		"memory-critical",
+		90,
+		"percentage of memory usage against the limit (the allocatable for nodes) to alert, or 0 to disable",
+	)
+	cmd.Flags().StringVar(
+		&ktop.alertRules,
+		"alert-rules",
+		"",
+		"file of alert rules, e.g. \"container memory > 0.9 * limit for 2m\" on each line",
+	)
+	cmd.Flags().StringVar(
+		&ktop.alertLog,
+		"alert-log",
+		"",
+		"file to append alerts to",
+	)
+	cmd.Flags().StringVar(
+		&ktop.alertWebhook,
+		"alert-webhook",
+		"",
+		"url to post alerts to as JSON",
+	)
+	cmd.Flags().BoolVar(
+		&ktop.alertBell,
+		"alert-bell",
+		true,
+		"ring the terminal bell when alerts fire",
+	)
 	ktop.k8sFlags = genericclioptions.NewConfigFlags()
-	ktop.k8sFlags.AddFlags(cmd.Flags())
+	ktop.k8sFlags.AddFlags(cmd.PersistentFlags())
 	if *ktop.k8sFlags.Namespace == "" {
 		*ktop.k8sFlags.Namespace = "default"
 	}
+	cmd.AddCommand(newServeCmd(&ktop))
+	cmd.AddCommand(newRecordCmd(&ktop))
+	cmd.AddCommand(newReplayCmd(&ktop))
 	return cmd
 }
 
@@ -93,47 +281,166 @@ func (k *ktopCmd) render(items ...termui.Drawable) {
 	termui.Render(items...)
 }
 
+// newSource connects to the cluster from the flags.
+func (k *ktopCmd) newSource() (ktop.Source, error) {
+	if k.allNamespaces {
+		*k.k8sFlags.Namespace = metav1.NamespaceAll
+	}
+	selectors, err := kube.NewSelectors(k.selector, k.fieldSelector, k.nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	metricsClient, err := kube.NewMetricsClient(k.k8sFlags, kube.MetricsSource(k.metricsSource), k.prometheusURL)
+	if err != nil {
+		return nil, err
+	}
+	kubeclients, err := kube.NewKubeClients(k.k8sFlags, selectors, metricsClient)
+	if err != nil {
+		return nil, err
+	}
+	return ktop.NewClusterSource(kubeclients, k.kubeletStats), nil
+}
+
+func (k *ktopCmd) newMonitor() (*ktop.Monitor, error) {
+	source, err := k.newSource()
+	if err != nil {
+		return nil, err
+	}
+	return ktop.NewMonitor(source, k.namespaceQuery, k.podQuery, k.containerQuery, k.nodeQuery), nil
+}
+
 func (k *ktopCmd) run(cmd *cobra.Command, args []string) error {
-	if err := termui.Init(); err != nil {
+	monitor, err := k.newMonitor()
+	if err != nil {
 		return err
 	}
-	defer termui.Close()
+	defer monitor.Close()
 
-	kubeclients, err := kube.NewKubeClients(k.k8sFlags)
+	if k.batch || k.output != "" {
+		return k.runBatch(monitor)
+	}
+	return k.runDashboard(monitor)
+}
+
+func (k *ktopCmd) runBatch(monitor *ktop.Monitor) error {
+	if err := monitor.FilterError(); err != nil {
+		return err
+	}
+	printer, err := ktop.NewPrinter(os.Stdout, k.table, k.output)
 	if err != nil {
 		return err
 	}
+	tick := time.NewTicker(k.interval)
+	defer tick.Stop()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt)
+
+	for i := 0; k.iterations <= 0 || i < k.iterations; i++ {
+		if i > 0 {
+			select {
+			case <-sigCh:
+				return nil
+			case <-tick.C:
+			}
+		}
+		if err := monitor.Collect(); err != nil {
+			return err
+		}
+		if err := printer.Print(monitor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// define queries
-	podQuery, err := regexp.Compile(k.podQuery)
+// setGraphScale configures the graphs from the flags.
+func (k *ktopCmd) setGraphScale(monitor *ktop.Monitor) error {
+	mode, err := ui.ParseScaleMode(k.graphScale)
 	if err != nil {
 		return err
 	}
-	containerQuery, err := regexp.Compile(k.containerQuery)
+	cpuMax, err := kr.ParseQuantity(k.cpuGraphMax)
+	if err != nil {
+		return errors.Wrap(err, "invalid --cpu-graph-max")
+	}
+	memMax, err := kr.ParseQuantity(k.memoryGraphMax)
+	if err != nil {
+		return errors.Wrap(err, "invalid --memory-graph-max")
+	}
+	monitor.SetGraphScale(mode, float64(cpuMax.MilliValue()), float64(memMax.Value()/(1024*1024)))
+	monitor.SetGraphInterval(k.interval)
+	return nil
+}
+
+func (k *ktopCmd) setSparkline(monitor *ktop.Monitor) error {
+	mode, err := ktop.ParseSparklineMode(k.sparkline)
 	if err != nil {
 		return err
 	}
-	nodeQuery, err := regexp.Compile(k.nodeQuery)
+	monitor.SetSparklineMode(mode)
+	return nil
+}
+
+// newAlertEngine returns the engine for the rules, or nil if no rules are given.
+func (k *ktopCmd) newAlertEngine() (*alert.Engine, error) {
+	if k.alertRules == "" {
+		return nil, nil
+	}
+	rules, err := alert.LoadRules(k.alertRules)
 	if err != nil {
+		return nil, err
+	}
+	notifiers := make([]alert.Notifier, 0)
+	if k.alertBell {
+		notifiers = append(notifiers, alert.NewBellNotifier(os.Stdout))
+	}
+	if k.alertLog != "" {
+		n, err := alert.NewLogNotifier(k.alertLog)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	if k.alertWebhook != "" {
+		notifiers = append(notifiers, alert.NewWebhookNotifier(k.alertWebhook))
+	}
+	return alert.NewEngine(rules, notifiers...), nil
+}
+
+// setDashboard configures the monitor from the flags for the dashboard.
+func (k *ktopCmd) setDashboard(monitor *ktop.Monitor) error {
+	if err := k.setGraphScale(monitor); err != nil {
 		return err
 	}
+	if err := k.setSparkline(monitor); err != nil {
+		return err
+	}
+	monitor.SetThresholds(k.thresholds)
+	return nil
+}
 
-	monitor := ktop.NewMonitor(kubeclients, podQuery, containerQuery, nodeQuery)
-	logo := ui.NewTextField()
-	logo.Text = logoStr
-	logo.TextStyle = termui.NewStyle(termui.ColorWhite, termui.ColorClear, termui.ModifierBold)
+// newGrid lays out the dashboard with the header on the top left.
+func (k *ktopCmd) newGrid(monitor *ktop.Monitor, header termui.Drawable) *termui.Grid {
 	hint := ui.NewTextField()
 	hint.Text = hintStr
 	hint.TextStyle = termui.NewStyle(termui.Color(244), termui.ColorClear)
 
+	logsRow := termui.NewRow(5./12, monitor.GetLogs())
+	if k.alertRules != "" {
+		logsRow = termui.NewRow(5./12,
+			termui.NewCol(2./3, monitor.GetLogs()),
+			termui.NewCol(1./3, monitor.GetAlerts()),
+		)
+	}
+
 	grid := termui.NewGrid()
 	grid.Set(
 		termui.NewRow(1./6,
-			termui.NewCol(1./2, logo),
+			termui.NewCol(1./2, header),
 			termui.NewCol(1./2, hint),
 		),
 		termui.NewRow(3./12, monitor.GetPodTable()),
-		termui.NewRow(5./12, monitor.GetLogs()),
+		logsRow,
 		termui.NewRow(2./12,
 			termui.NewCol(1./2, monitor.GetCPUGraph()),
 			termui.NewCol(1./2, monitor.GetMemGraph()),
@@ -141,6 +448,27 @@ func (k *ktopCmd) run(cmd *cobra.Command, args []string) error {
 	)
 	termWidth, termHeight := termui.TerminalDimensions()
 	grid.SetRect(0, 0, termWidth, termHeight)
+	return grid
+}
+
+func (k *ktopCmd) runDashboard(monitor *ktop.Monitor) error {
+	if err := k.setDashboard(monitor); err != nil {
+		return err
+	}
+	engine, err := k.newAlertEngine()
+	if err != nil {
+		return err
+	}
+	monitor.SetAlertEngine(engine)
+	if err := termui.Init(); err != nil {
+		return err
+	}
+	defer termui.Close()
+
+	logo := ui.NewTextField()
+	logo.Text = logoStr
+	logo.TextStyle = termui.NewStyle(termui.ColorWhite, termui.ColorClear, termui.ModifierBold)
+	grid := k.newGrid(monitor, logo)
 
 	events := termui.PollEvents()
 	tick := time.NewTicker(k.interval)
@@ -152,30 +480,91 @@ func (k *ktopCmd) run(cmd *cobra.Command, args []string) error {
 		case <-sigCh:
 			return nil
 		case <-tick.C:
-			if err := monitor.Update(); err != nil {
-				return err
-			}
+			monitor.Update()
 		case e := <-events:
-			switch e.ID {
-			case "<Down>":
-				monitor.ScrollDown()
-			case "<Up>":
-				monitor.ScrollUp()
-			case "<Right>":
-				monitor.Rotate()
-			case "<Left>":
-				monitor.ReverseRotate()
-			case "q", "<C-c>":
+			if quit := k.handleEvent(monitor, grid, e); quit {
 				return nil
-			case "<Resize>":
-				termWidth, termHeight := termui.TerminalDimensions()
-				grid.SetRect(0, 0, termWidth, termHeight)
 			}
 		}
+		k.renderDashboard(monitor, grid)
+	}
+}
+
+// handleEvent operates the monitor by the event, and returns true to quit.
+func (k *ktopCmd) handleEvent(monitor *ktop.Monitor, grid *termui.Grid, e termui.Event) bool {
+	if monitor.IsFiltering() && e.Type == termui.KeyboardEvent {
+		k.inputFilter(monitor, e)
+		return false
+	}
+	switch e.ID {
+	case "<Down>":
+		monitor.ScrollDown()
+	case "<Up>":
+		monitor.ScrollUp()
+	case "<Right>":
+		monitor.Rotate()
+	case "<Left>":
+		monitor.ReverseRotate()
+	case "<Enter>":
+		monitor.DrillDown()
+	case "<Escape>":
+		monitor.GoBack()
+	case "<Space>":
+		monitor.ToggleMark()
+	case "s":
+		monitor.CycleSort()
+	case "S":
+		monitor.ReverseSort()
+	case "/":
+		monitor.OpenFilter()
+	case "n":
+		monitor.OpenNamespaceFilter()
+	case "a":
+		monitor.CycleGraphScale()
+	case "t":
+		monitor.CycleSparkline()
+	case "g":
+		monitor.CycleGraph()
+	case "q", "<C-c>":
+		return true
+	case "<Resize>":
+		termWidth, termHeight := termui.TerminalDimensions()
+		grid.SetRect(0, 0, termWidth, termHeight)
+	}
+	return false
+}
+
+func (k *ktopCmd) renderDashboard(monitor *ktop.Monitor, grid *termui.Grid) {
+	if monitor.IsFiltering() {
+		// draw the prompt on the bottom border of the table
+		rect := monitor.GetPodTable().GetRect()
+		prompt := monitor.GetFilterPrompt()
+		prompt.SetRect(rect.Min.X+1, rect.Max.Y-1, rect.Max.X-1, rect.Max.Y)
+		k.render(grid, prompt)
+	} else {
 		k.render(grid)
 	}
 }
 
+func (k *ktopCmd) inputFilter(monitor *ktop.Monitor, e termui.Event) {
+	switch e.ID {
+	case "<Enter>":
+		monitor.CloseFilter(false)
+	case "<Escape>", "<C-c>":
+		monitor.CloseFilter(true)
+	case "<Backspace>", "<C-<Backspace>>":
+		monitor.BackspaceFilter()
+	case "<Tab>":
+		monitor.CycleFilterMode()
+	case "<Space>":
+		monitor.InsertFilter(" ")
+	default:
+		if utf8.RuneCountInString(e.ID) == 1 {
+			monitor.InsertFilter(e.ID)
+		}
+	}
+}
+
 func Execute() {
 	rootCmd := newKtopCmd()
 	if err := rootCmd.Execute(); err != nil {
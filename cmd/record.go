@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/ktop/pkg/ktop"
+)
+
+type recordCmd struct {
+	*ktopCmd
+	file string
+}
+
+func newRecordCmd(k *ktopCmd) *cobra.Command {
+	record := recordCmd{ktopCmd: k}
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record snapshots of the cluster to a session file to replay",
+		RunE:  record.run,
+	}
+	cmd.Flags().StringVarP(
+		&record.file,
+		"file",
+		"f",
+		"",
+		"new session file to write snapshots to",
+	)
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func (r *recordCmd) run(cmd *cobra.Command, args []string) error {
+	source, err := r.newSource()
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	writer, err := ktop.NewSessionWriter(r.file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		writer.Close()
+		fmt.Fprintf(os.Stderr, "recorded %v snapshots to %v\n", writer.Len(), r.file)
+	}()
+
+	tick := time.NewTicker(r.interval)
+	defer tick.Stop()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt)
+
+	for {
+		snapshot, err := source.Snapshot()
+		if err != nil {
+			// keep recording on failure, e.g. the metrics are not ready yet
+			fmt.Fprintln(os.Stderr, err)
+		} else if snapshot != nil {
+			if err := writer.Write(snapshot); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-sigCh:
+			return nil
+		case <-tick.C:
+		}
+	}
+}
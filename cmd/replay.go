@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gizak/termui/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/ktop/pkg/ktop"
+	"github.com/ynqa/ktop/pkg/ui"
+)
+
+const (
+	replayHintStr = `<p> Pause  <.>, <,> Step  <+>, <-> Speed
+<[>, <]> Seek 1m  <Home>, <End> Jump`
+	// snapshots replayed before the position after seeking to draw the graphs
+	replayWarmUp = 30
+	// longest wait between snapshots, e.g. over a gap where the recording failed to fetch
+	maxReplayDelay = 5 * time.Second
+	replaySeekStep = time.Minute
+)
+
+var (
+	replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16}
+)
+
+type replayCmd struct {
+	*ktopCmd
+	file string
+}
+
+func newReplayCmd(k *ktopCmd) *cobra.Command {
+	replay := replayCmd{ktopCmd: k}
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay a session file recorded by the record command on the dashboard",
+		RunE:  replay.run,
+	}
+	cmd.Flags().StringVarP(
+		&replay.file,
+		"file",
+		"f",
+		"",
+		"session file to replay",
+	)
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+// replayer drives the monitor by the snapshots of the player.
+type replayer struct {
+	monitor *ktop.Monitor
+	player  *ktop.Player
+	file    string
+	paused  bool
+	speed   int
+	status  *ui.TextField
+}
+
+func (r *replayCmd) run(cmd *cobra.Command, args []string) error {
+	player, err := ktop.OpenPlayer(r.file)
+	if err != nil {
+		return err
+	}
+	monitor := ktop.NewMonitor(player, r.namespaceQuery, r.podQuery, r.containerQuery, r.nodeQuery)
+	defer monitor.Close()
+	if err := monitor.FilterError(); err != nil {
+		return err
+	}
+	if err := r.setDashboard(monitor); err != nil {
+		return err
+	}
+	if interval := player.Interval(); interval > 0 {
+		monitor.SetGraphInterval(interval)
+	}
+	if err := termui.Init(); err != nil {
+		return err
+	}
+	defer termui.Close()
+
+	status := ui.NewTextField()
+	status.TextStyle = termui.NewStyle(termui.ColorWhite, termui.ColorClear, termui.ModifierBold)
+	rp := &replayer{
+		monitor: monitor,
+		player:  player,
+		file:    r.file,
+		speed:   2,
+		status:  status,
+	}
+	grid := r.newGrid(monitor, status)
+	if err := rp.update(); err != nil {
+		return err
+	}
+	r.renderDashboard(monitor, grid)
+
+	events := termui.PollEvents()
+	timer := time.NewTimer(rp.delay())
+	defer timer.Stop()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt)
+
+	for {
+		// whether to wait for the next snapshot from the current one again,
+		// which is only on the operations of the replay not to delay it by the other keys
+		var reset bool
+		select {
+		case <-sigCh:
+			return nil
+		case <-timer.C:
+			reset = true
+			if !rp.paused {
+				if err := rp.step(1); err != nil {
+					return err
+				}
+			}
+		case e := <-events:
+			handled, err := rp.handleEvent(e)
+			if err != nil {
+				return err
+			}
+			reset = handled
+			if !handled {
+				if quit := r.handleEvent(monitor, grid, e); quit {
+					return nil
+				}
+			}
+		}
+		if reset {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(rp.delay())
+		}
+		rp.updateStatus()
+		r.renderDashboard(monitor, grid)
+	}
+}
+
+// handleEvent operates the replay by the event, and returns true if the event is consumed.
+func (r *replayer) handleEvent(e termui.Event) (bool, error) {
+	if r.monitor.IsFiltering() {
+		return false, nil
+	}
+	pos := r.player.Position()
+	switch e.ID {
+	case "p":
+		r.paused = !r.paused
+		return true, nil
+	case ".":
+		r.paused = true
+		return true, r.step(1)
+	case ",":
+		r.paused = true
+		return true, r.seek(pos - 1)
+	case "+":
+		if r.speed < len(replaySpeeds)-1 {
+			r.speed++
+		}
+		return true, nil
+	case "-":
+		if r.speed > 0 {
+			r.speed--
+		}
+		return true, nil
+	case "[":
+		r.player.SeekTime(r.player.Time(pos).Add(-replaySeekStep))
+		return true, r.seek(r.player.Position())
+	case "]":
+		r.player.SeekTime(r.player.Time(pos).Add(replaySeekStep))
+		return true, r.seek(r.player.Position())
+	case "<Home>":
+		return true, r.seek(0)
+	case "<End>":
+		return true, r.seek(r.player.Len() - 1)
+	}
+	return false, nil
+}
+
+// step replays the next snapshots, and pauses at the end of the session.
+func (r *replayer) step(n int) error {
+	pos := r.player.Position()
+	if pos+n >= r.player.Len() {
+		r.paused = true
+		return nil
+	}
+	r.player.Seek(pos + n)
+	return r.update()
+}
+
+// seek jumps to the snapshot, and replays some snapshots before it
+// not to leave the graphs empty, since the history is not continuous.
+func (r *replayer) seek(pos int) error {
+	r.player.Seek(pos)
+	pos = r.player.Position()
+	r.monitor.ResetHistory()
+	for i := pos - replayWarmUp; i < pos; i++ {
+		if i < 0 {
+			continue
+		}
+		r.player.Seek(i)
+		if err := r.monitor.Collect(); err != nil {
+			return err
+		}
+	}
+	r.player.Seek(pos)
+	return r.update()
+}
+
+func (r *replayer) update() error {
+	r.monitor.Update()
+	r.updateStatus()
+	return nil
+}
+
+// delay returns the time to wait for the next snapshot at the speed.
+func (r *replayer) delay() time.Duration {
+	pos := r.player.Position()
+	if pos+1 >= r.player.Len() {
+		return maxReplayDelay
+	}
+	d := time.Duration(float64(r.player.Time(pos+1).Sub(r.player.Time(pos))) / replaySpeeds[r.speed])
+	if d > maxReplayDelay {
+		d = maxReplayDelay
+	}
+	return d
+}
+
+func (r *replayer) updateStatus() {
+	pos := r.player.Position()
+	state := "Playing"
+	if r.paused {
+		state = "Paused"
+	}
+	r.status.Text = fmt.Sprintf("\nReplaying %v\n\nSnapshot: %v/%v (%v)\nElapsed:  %v\nState:    %v x%v\n\n%v",
+		r.file,
+		pos+1, r.player.Len(), r.player.Time(pos).Local().Format("2006-01-02 15:04:05"),
+		r.player.Time(pos).Sub(r.player.Time(0)).Round(time.Second),
+		state, replaySpeeds[r.speed],
+		replayHintStr,
+	)
+}
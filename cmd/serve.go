@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/ktop/pkg/ktop"
+)
+
+type serveCmd struct {
+	*ktopCmd
+	listen string
+}
+
+func newServeCmd(k *ktopCmd) *cobra.Command {
+	serve := serveCmd{ktopCmd: k}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve collected resources as Prometheus metrics",
+		RunE:  serve.run,
+	}
+	cmd.Flags().StringVar(
+		&serve.listen,
+		"listen",
+		":9100",
+		"address to serve /metrics",
+	)
+	return cmd
+}
+
+func (s *serveCmd) run(cmd *cobra.Command, args []string) error {
+	monitor, err := s.newMonitor()
+	if err != nil {
+		return err
+	}
+	defer monitor.Close()
+	if err := monitor.FilterError(); err != nil {
+		return err
+	}
+
+	exporter := ktop.NewExporter(monitor)
+	if err := exporter.Update(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{
+		Addr:    s.listen,
+		Handler: mux,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	defer server.Close()
+
+	tick := time.NewTicker(s.interval)
+	defer tick.Stop()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt)
+
+	for {
+		select {
+		case <-sigCh:
+			return nil
+		case err := <-errCh:
+			return err
+		case <-tick.C:
+			// keep serving the previous metrics on failure
+			if err := exporter.Update(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+}
@@ -38,6 +38,7 @@ require (
 	k8s.io/cli-runtime v0.0.0-20190228180923-a9e421a79326
 	k8s.io/client-go v0.0.0-20190228174230-b40b2a5939e4
 	k8s.io/klog v0.2.0 // indirect
+	k8s.io/kube-openapi v0.0.0-20190816220812-743ec37842bf // indirect
 	k8s.io/kubernetes v1.13.4
 	k8s.io/metrics v0.0.0-20190228180609-34472d076c30
 	sigs.k8s.io/yaml v1.1.0 // indirect
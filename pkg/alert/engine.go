@@ -0,0 +1,221 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// number of events kept to show
+	maxRecentEvents = 50
+)
+
+// Value is the usage of a resource with its limit and request.
+// The limit of nodes is the allocatable.
+// HasUsage is false if the metrics of the resource are not reported yet,
+// which is neither compared nor taken as the resource being gone.
+type Value struct {
+	HasUsage   bool
+	Usage      float64
+	Limit      float64
+	HasLimit   bool
+	Request    float64
+	HasRequest bool
+}
+
+// Sample is the usages of a resource at an update.
+type Sample struct {
+	Key    string
+	CPU    Value
+	Memory Value
+}
+
+type State string
+
+const (
+	Firing   State = "firing"
+	Resolved State = "resolved"
+)
+
+// Event is a transition of an alert.
+type Event struct {
+	State     State     `json:"status"`
+	Rule      string    `json:"rule"`
+	Target    Target    `json:"target"`
+	Resource  string    `json:"resource"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	StartsAt  time.Time `json:"startsAt"`
+	Time      time.Time `json:"time"`
+
+	rule *Rule
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%v %v: %v (value %v, threshold %v)",
+		strings.ToUpper(string(e.State)), e.Resource, e.Rule,
+		e.rule.format(e.Value), e.rule.format(e.Threshold))
+}
+
+type alertKey struct {
+	rule     int
+	resource string
+}
+
+// alertState is a resource which meets the condition of a rule.
+type alertState struct {
+	since  time.Time
+	firing bool
+	event  Event
+	seen   bool
+}
+
+// Engine evaluates the rules against the usages of each update,
+// and notifies the alerts which start or stop firing.
+type Engine struct {
+	rules     []*Rule
+	notifiers []Notifier
+	alerts    map[alertKey]*alertState
+	recent    []Event
+	err       error
+}
+
+func NewEngine(rules []*Rule, notifiers ...Notifier) *Engine {
+	return &Engine{
+		rules:     rules,
+		notifiers: notifiers,
+		alerts:    make(map[alertKey]*alertState),
+	}
+}
+
+// Evaluate evaluates the rules against the samples of each target at the time,
+// and returns the transitions of the alerts after notifying them.
+func (e *Engine) Evaluate(now time.Time, samples map[Target][]Sample) []Event {
+	events := make([]Event, 0)
+	for _, state := range e.alerts {
+		state.seen = false
+	}
+	for i, rule := range e.rules {
+		for _, s := range samples[rule.Target] {
+			key := alertKey{rule: i, resource: s.Key}
+			state, active := e.alerts[key]
+			// kept as it is until the metrics are reported
+			if !rule.value(s).HasUsage {
+				if active {
+					state.seen = true
+				}
+				continue
+			}
+			value, threshold, matched, ok := rule.evaluate(s)
+			if !ok || !matched {
+				if active && state.firing {
+					events = append(events, state.resolve(now, value))
+				}
+				delete(e.alerts, key)
+				continue
+			}
+			if !active {
+				state = &alertState{since: now}
+				e.alerts[key] = state
+			}
+			state.seen = true
+			state.event = Event{
+				State:     Firing,
+				Rule:      rule.Text,
+				Target:    rule.Target,
+				Resource:  s.Key,
+				Value:     value,
+				Threshold: threshold,
+				StartsAt:  state.since,
+				Time:      now,
+				rule:      rule,
+			}
+			if !state.firing && now.Sub(state.since) >= rule.For {
+				state.firing = true
+				events = append(events, state.event)
+			}
+		}
+	}
+	// the resources which are gone
+	for key, state := range e.alerts {
+		if state.seen {
+			continue
+		}
+		if state.firing {
+			events = append(events, state.resolve(now, state.event.Value))
+		}
+		delete(e.alerts, key)
+	}
+
+	e.record(events)
+	e.notify(events)
+	return events
+}
+
+func (s *alertState) resolve(now time.Time, value float64) Event {
+	event := s.event
+	event.State = Resolved
+	event.Value = value
+	event.Time = now
+	return event
+}
+
+func (e *Engine) record(events []Event) {
+	e.recent = append(e.recent, events...)
+	if n := len(e.recent); n > maxRecentEvents {
+		e.recent = e.recent[n-maxRecentEvents:]
+	}
+}
+
+func (e *Engine) notify(events []Event) {
+	for _, event := range events {
+		for _, n := range e.notifiers {
+			if err := n.Notify(event); err != nil {
+				e.err = err
+			}
+		}
+	}
+}
+
+// Firing returns the alerts firing now in the order of starting.
+func (e *Engine) Firing() []Event {
+	firing := make([]Event, 0)
+	for _, state := range e.alerts {
+		if state.firing {
+			firing = append(firing, state.event)
+		}
+	}
+	sort.SliceStable(firing, func(i, j int) bool {
+		if !firing[i].StartsAt.Equal(firing[j].StartsAt) {
+			return firing[i].StartsAt.Before(firing[j].StartsAt)
+		}
+		return firing[i].Resource < firing[j].Resource
+	})
+	return firing
+}
+
+// Recent returns the latest transitions, the newest first.
+func (e *Engine) Recent() []Event {
+	recent := make([]Event, len(e.recent))
+	for i, event := range e.recent {
+		recent[len(e.recent)-1-i] = event
+	}
+	return recent
+}
+
+// Err returns the last error of notifying.
+func (e *Engine) Err() error {
+	return e.err
+}
+
+func (e *Engine) Close() error {
+	var err error
+	for _, n := range e.notifiers {
+		if cerr := n.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
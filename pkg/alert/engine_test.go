@@ -0,0 +1,136 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+type recordingNotifier struct {
+	events []Event
+}
+
+func (n *recordingNotifier) Notify(event Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *recordingNotifier) Close() error {
+	return nil
+}
+
+func cpuSamples(usages map[string]float64) map[Target][]Sample {
+	samples := make([]Sample, 0, len(usages))
+	for key, usage := range usages {
+		samples = append(samples, Sample{Key: key, CPU: Value{HasUsage: true, Usage: usage}})
+	}
+	return map[Target][]Sample{PodTarget: samples}
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	rule, err := ParseRule("pod cpu > 500 for 2m")
+	if err != nil {
+		t.Fatalf("ParseRule() error = %v", err)
+	}
+	notifier := &recordingNotifier{}
+	engine := NewEngine([]*Rule{rule}, notifier)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		elapsed time.Duration
+		usages  map[string]float64
+		// transitions of the update, and the alerts firing after it
+		want   []State
+		firing int
+	}{
+		// pending until the condition holds for 2m
+		{elapsed: 0, usages: map[string]float64{"default/web": 600, "default/db": 100}},
+		{elapsed: time.Minute, usages: map[string]float64{"default/web": 700, "default/db": 100}},
+		{elapsed: 2 * time.Minute, usages: map[string]float64{"default/web": 800, "default/db": 100}, want: []State{Firing}, firing: 1},
+		// firing is notified once
+		{elapsed: 3 * time.Minute, usages: map[string]float64{"default/web": 800, "default/db": 100}, firing: 1},
+		{elapsed: 4 * time.Minute, usages: map[string]float64{"default/web": 300, "default/db": 100}, want: []State{Resolved}},
+		// the pending is reset by the resolution
+		{elapsed: 5 * time.Minute, usages: map[string]float64{"default/web": 600}},
+		{elapsed: 6 * time.Minute, usages: map[string]float64{"default/web": 600}},
+		{elapsed: 7 * time.Minute, usages: map[string]float64{"default/web": 600}, want: []State{Firing}, firing: 1},
+		// the resources which are gone are resolved
+		{elapsed: 8 * time.Minute, usages: map[string]float64{}, want: []State{Resolved}},
+	}
+	var notified int
+	for _, step := range steps {
+		now := start.Add(step.elapsed)
+		events := engine.Evaluate(now, cpuSamples(step.usages))
+		if len(events) != len(step.want) {
+			t.Fatalf("at %v, events = %v, want %v", step.elapsed, events, step.want)
+		}
+		for i, event := range events {
+			if event.State != step.want[i] || event.Resource != "default/web" || !event.Time.Equal(now) {
+				t.Errorf("at %v, event = %+v, want %v of default/web", step.elapsed, event, step.want[i])
+			}
+		}
+		if firing := engine.Firing(); len(firing) != step.firing {
+			t.Errorf("at %v, firing = %v, want %v alerts", step.elapsed, firing, step.firing)
+		}
+		notified += len(events)
+	}
+	if len(notifier.events) != notified {
+		t.Errorf("notified %v events, want %v", len(notifier.events), notified)
+	}
+
+	firing := notifier.events[0]
+	if !firing.StartsAt.Equal(start) || firing.Value != 800 || firing.Threshold != 500 {
+		t.Errorf("firing event = %+v, want started at %v with value 800 and threshold 500", firing, start)
+	}
+	resolved := notifier.events[1]
+	if !resolved.StartsAt.Equal(start) || resolved.Value != 300 {
+		t.Errorf("resolved event = %+v, want started at %v with value 300", resolved, start)
+	}
+	if recent := engine.Recent(); len(recent) != notified || recent[0].State != Resolved {
+		t.Errorf("recent = %v, want %v events with the newest first", recent, notified)
+	}
+}
+
+func TestEngineEvaluateWithoutUsage(t *testing.T) {
+	rule, err := ParseRule("pod cpu < 100 for 1m")
+	if err != nil {
+		t.Fatalf("ParseRule() error = %v", err)
+	}
+	engine := NewEngine([]*Rule{rule})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	noUsage := map[Target][]Sample{PodTarget: {{Key: "default/web"}}}
+
+	steps := []struct {
+		elapsed time.Duration
+		samples map[Target][]Sample
+		want    []State
+		firing  int
+	}{
+		// the zero usage of the pod without metrics is not compared
+		{elapsed: 0, samples: noUsage},
+		{elapsed: 2 * time.Minute, samples: noUsage},
+		// the pending is kept while the metrics are missing
+		{elapsed: 3 * time.Minute, samples: cpuSamples(map[string]float64{"default/web": 50})},
+		{elapsed: 4 * time.Minute, samples: noUsage},
+		{elapsed: 5 * time.Minute, samples: cpuSamples(map[string]float64{"default/web": 50}), want: []State{Firing}, firing: 1},
+		// the firing is not resolved while the metrics are missing
+		{elapsed: 6 * time.Minute, samples: noUsage, firing: 1},
+		{elapsed: 7 * time.Minute, samples: cpuSamples(map[string]float64{"default/web": 500}), want: []State{Resolved}},
+	}
+	for _, step := range steps {
+		events := engine.Evaluate(start.Add(step.elapsed), step.samples)
+		if len(events) != len(step.want) {
+			t.Fatalf("at %v, events = %v, want %v", step.elapsed, events, step.want)
+		}
+		for i, event := range events {
+			if event.State != step.want[i] {
+				t.Errorf("at %v, event = %+v, want %v", step.elapsed, event, step.want[i])
+			}
+		}
+		if firing := engine.Firing(); len(firing) != step.firing {
+			t.Errorf("at %v, firing = %v, want %v alerts", step.elapsed, firing, step.firing)
+		}
+	}
+	if firing := engine.Recent()[1]; !firing.StartsAt.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("firing event = %+v, want started at the first sample with usage", firing)
+	}
+}
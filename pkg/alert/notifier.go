@@ -0,0 +1,143 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	webhookTimeout = 5 * time.Second
+	// events waiting to be posted
+	webhookQueueSize = 100
+)
+
+// Notifier tells the transitions of alerts.
+type Notifier interface {
+	Notify(event Event) error
+	Close() error
+}
+
+type bellNotifier struct {
+	w io.Writer
+}
+
+// NewBellNotifier rings the terminal bell when alerts start firing.
+func NewBellNotifier(w io.Writer) Notifier {
+	return &bellNotifier{w: w}
+}
+
+func (n *bellNotifier) Notify(event Event) error {
+	if event.State != Firing {
+		return nil
+	}
+	_, err := io.WriteString(n.w, "\a")
+	return err
+}
+
+func (n *bellNotifier) Close() error {
+	return nil
+}
+
+type logNotifier struct {
+	f *os.File
+}
+
+// NewLogNotifier appends the events to the file.
+func NewLogNotifier(path string) (Notifier, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &logNotifier{f: f}, nil
+}
+
+func (n *logNotifier) Notify(event Event) error {
+	_, err := fmt.Fprintf(n.f, "%v %v\n", event.Time.Format(time.RFC3339), event)
+	return err
+}
+
+func (n *logNotifier) Close() error {
+	return n.f.Close()
+}
+
+// webhookNotifier posts the events in background not to block updates.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewWebhookNotifier posts each event to the url as JSON.
+func NewWebhookNotifier(url string) Notifier {
+	n := &webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan Event, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+func (n *webhookNotifier) run() {
+	defer close(n.done)
+	for event := range n.queue {
+		if err := n.post(event); err != nil {
+			n.mu.Lock()
+			n.err = err
+			n.mu.Unlock()
+		}
+	}
+}
+
+func (n *webhookNotifier) post(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to post alert to webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded %v", resp.Status)
+	}
+	return nil
+}
+
+// Notify queues the event, and returns the error of the previous posts if any.
+func (n *webhookNotifier) Notify(event Event) error {
+	select {
+	case n.queue <- event:
+	default:
+		return errors.New("webhook queue is full")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	err := n.err
+	n.err = nil
+	return err
+}
+
+// Close waits for the queued events to be posted.
+func (n *webhookNotifier) Close() error {
+	close(n.queue)
+	select {
+	case <-n.done:
+	case <-time.After(webhookTimeout):
+	}
+	return nil
+}
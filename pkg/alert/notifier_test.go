@@ -0,0 +1,109 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %v, want application/json", ct)
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	rule, err := ParseRule("node cpu% > 85 for 5m")
+	if err != nil {
+		t.Fatalf("ParseRule() error = %v", err)
+	}
+	startsAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := NewWebhookNotifier(server.URL)
+	if err := n.Notify(Event{
+		State:     Firing,
+		Rule:      rule.Text,
+		Target:    rule.Target,
+		Resource:  "node-1",
+		Value:     90,
+		Threshold: 85,
+		StartsAt:  startsAt,
+		Time:      startsAt.Add(5 * time.Minute),
+		rule:      rule,
+	}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		want := map[string]interface{}{
+			"status":    "firing",
+			"rule":      "node cpu% > 85 for 5m",
+			"target":    "node",
+			"resource":  "node-1",
+			"value":     float64(90),
+			"threshold": float64(85),
+			"startsAt":  "2020-01-01T00:00:00Z",
+			"time":      "2020-01-01T00:05:00Z",
+		}
+		if len(payload) != len(want) {
+			t.Errorf("payload = %v, want %v", payload, want)
+		}
+		for key, value := range want {
+			if payload[key] != value {
+				t.Errorf("%v of payload = %v, want %v", key, payload[key], value)
+			}
+		}
+	case <-time.After(webhookTimeout):
+		t.Fatal("webhook is not posted")
+	}
+	if err := n.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestWebhookNotifierQueueFull(t *testing.T) {
+	// the webhook hangs until the test ends
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	n := NewWebhookNotifier(server.URL)
+	event := Event{State: Firing, Resource: "node-1"}
+	done := make(chan error, 1)
+	go func() {
+		// one is being posted and the others fill the queue
+		for i := 0; i < webhookQueueSize+1; i++ {
+			if err := n.Notify(event); err != nil {
+				done <- err
+				return
+			}
+			if i == 0 {
+				// wait for the first event to be taken from the queue
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+		done <- n.Notify(event)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "queue is full") {
+			t.Errorf("Notify() error = %v, want the full queue", err)
+		}
+	case <-time.After(webhookTimeout):
+		t.Fatal("Notify() blocks on the full queue")
+	}
+}
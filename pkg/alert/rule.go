@@ -0,0 +1,222 @@
+package alert
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Target is the kind of resources which a rule is evaluated against.
+type Target string
+
+const (
+	ContainerTarget Target = "container"
+	PodTarget       Target = "pod"
+	NodeTarget      Target = "node"
+	WorkloadTarget  Target = "workload"
+	NamespaceTarget Target = "namespace"
+)
+
+var (
+	targets = []Target{ContainerTarget, PodTarget, NodeTarget, WorkloadTarget, NamespaceTarget}
+	// operators which may be written without spaces
+	ruleTokens = regexp.MustCompile(`(>=|<=|>|<|\*)`)
+)
+
+// reference is the value of the resource which the threshold is relative to.
+type reference string
+
+const (
+	noReference      reference = ""
+	limitReference   reference = "limit"
+	requestReference reference = "request"
+)
+
+// Rule is a condition on the usage of resources, e.g.
+// "container memory > 0.9 * limit for 2m" or "node cpu% > 85 for 5m".
+//
+// The usages are in millicores for cpu and Mi for memory,
+// and "cpu%"/"memory%" are the usages against the limits, or the allocatable of nodes.
+type Rule struct {
+	Text   string
+	Target Target
+	// "cpu" or "memory"
+	Resource   string
+	Percentage bool
+	Operator   string
+	// the threshold is Factor * the reference, or Factor if there is no reference
+	Factor    float64
+	Reference reference
+	// how long the condition has to hold before firing
+	For time.Duration
+}
+
+// ParseRule parses the rule in the form of "<target> <metric> <operator> [<factor> *] <value> [for <duration>]".
+func ParseRule(text string) (*Rule, error) {
+	text = strings.TrimSpace(text)
+	fields := strings.Fields(ruleTokens.ReplaceAllString(text, " $1 "))
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid rule: %v", text)
+	}
+	rule := &Rule{Text: text}
+
+	rule.Target = Target(fields[0])
+	if !validTarget(rule.Target) {
+		return nil, fmt.Errorf("unknown target %v in rule: %v", fields[0], text)
+	}
+
+	metric := fields[1]
+	rule.Percentage = strings.HasSuffix(metric, "%")
+	rule.Resource = strings.TrimSuffix(metric, "%")
+	if rule.Resource != "cpu" && rule.Resource != "memory" {
+		return nil, fmt.Errorf("unknown metric %v in rule: %v", metric, text)
+	}
+
+	rule.Operator = fields[2]
+	switch rule.Operator {
+	case ">", ">=", "<", "<=":
+	default:
+		return nil, fmt.Errorf("unknown operator %v in rule: %v", fields[2], text)
+	}
+
+	rest := fields[3:]
+	for i, f := range rest {
+		if f != "for" {
+			continue
+		}
+		if i != len(rest)-2 {
+			return nil, fmt.Errorf("invalid duration in rule: %v", text)
+		}
+		d, err := time.ParseDuration(rest[i+1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid duration in rule: %v", text)
+		}
+		rule.For = d
+		rest = rest[:i]
+		break
+	}
+
+	var err error
+	switch {
+	case len(rest) == 1 && isReference(rest[0]):
+		rule.Factor, rule.Reference = 1, reference(rest[0])
+	case len(rest) == 1:
+		rule.Factor, err = strconv.ParseFloat(rest[0], 64)
+	case len(rest) == 3 && rest[1] == "*" && isReference(rest[2]):
+		rule.Reference = reference(rest[2])
+		rule.Factor, err = strconv.ParseFloat(rest[0], 64)
+	default:
+		return nil, fmt.Errorf("invalid threshold in rule: %v", text)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid threshold in rule: %v", text)
+	}
+	if rule.Percentage && rule.Reference != noReference {
+		return nil, fmt.Errorf("percentage can not be compared with %v in rule: %v", rule.Reference, text)
+	}
+	return rule, nil
+}
+
+func validTarget(target Target) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+func isReference(s string) bool {
+	return s == string(limitReference) || s == string(requestReference)
+}
+
+// LoadRules reads the rules from the file, which has a rule on each line.
+// Empty lines and lines starting with "#" are ignored.
+func LoadRules(path string) ([]*Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := make([]*Rule, 0)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := ParseRule(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%v:%v", path, n)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// value returns the value of the resource of the rule in the sample.
+func (r *Rule) value(s Sample) Value {
+	if r.Resource == "memory" {
+		return s.Memory
+	}
+	return s.CPU
+}
+
+// evaluate returns the value and the threshold of the sample,
+// and whether the sample meets the condition.
+// ok is false if the sample does not have the values to compare, e.g. limits.
+func (r *Rule) evaluate(s Sample) (value, threshold float64, matched, ok bool) {
+	v := r.value(s)
+	value = v.Usage
+	if r.Percentage {
+		if !v.HasLimit || v.Limit == 0 {
+			return 0, 0, false, false
+		}
+		value = v.Usage / v.Limit * 100
+	}
+
+	threshold = r.Factor
+	switch r.Reference {
+	case limitReference:
+		if !v.HasLimit {
+			return 0, 0, false, false
+		}
+		threshold *= v.Limit
+	case requestReference:
+		if !v.HasRequest {
+			return 0, 0, false, false
+		}
+		threshold *= v.Request
+	}
+
+	switch r.Operator {
+	case ">":
+		matched = value > threshold
+	case ">=":
+		matched = value >= threshold
+	case "<":
+		matched = value < threshold
+	case "<=":
+		matched = value <= threshold
+	}
+	return value, threshold, matched, true
+}
+
+// format formats the value in the unit of the metric.
+func (r *Rule) format(v float64) string {
+	switch {
+	case r.Percentage:
+		return fmt.Sprintf("%.1f%%", v)
+	case r.Resource == "memory":
+		return fmt.Sprintf("%.0fMi", v)
+	default:
+		return fmt.Sprintf("%.0fm", v)
+	}
+}
@@ -0,0 +1,104 @@
+package alert
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Rule
+		wantErr string
+	}{
+		{
+			text: "container memory > 0.9 * limit for 2m",
+			want: Rule{Target: ContainerTarget, Resource: "memory", Operator: ">", Factor: 0.9, Reference: limitReference, For: 2 * time.Minute},
+		},
+		{
+			text: "node cpu% >= 85 for 5m",
+			want: Rule{Target: NodeTarget, Resource: "cpu", Percentage: true, Operator: ">=", Factor: 85, For: 5 * time.Minute},
+		},
+		{
+			text: "pod cpu<request",
+			want: Rule{Target: PodTarget, Resource: "cpu", Operator: "<", Factor: 1, Reference: requestReference},
+		},
+		{
+			text: "  namespace memory<=2*request  ",
+			want: Rule{Target: NamespaceTarget, Resource: "memory", Operator: "<=", Factor: 2, Reference: requestReference},
+		},
+		{
+			text: "workload cpu > 500",
+			want: Rule{Target: WorkloadTarget, Resource: "cpu", Operator: ">", Factor: 500},
+		},
+		{text: "container cpu >", wantErr: "invalid rule"},
+		{text: "cluster cpu > 500", wantErr: "unknown target"},
+		{text: "pod disk > 500", wantErr: "unknown metric"},
+		{text: "pod cpu == 500", wantErr: "unknown operator"},
+		{text: "pod cpu != 500", wantErr: "unknown operator"},
+		{text: "pod cpu => 500", wantErr: "unknown operator"},
+		// the usages are in millicores and Mi without units
+		{text: "pod cpu > 500m", wantErr: "invalid threshold"},
+		{text: "pod memory > 1Gi", wantErr: "invalid threshold"},
+		{text: "pod memory > 0.9 * allocatable", wantErr: "invalid threshold"},
+		{text: "pod memory > 0.9 limit", wantErr: "invalid threshold"},
+		{text: "pod cpu% > 0.9 * limit", wantErr: "percentage can not be compared"},
+		{text: "pod cpu > 500 for 2", wantErr: "invalid duration"},
+		{text: "pod cpu > 500 for minutes", wantErr: "invalid duration"},
+		{text: "pod cpu > 500 for", wantErr: "invalid duration"},
+		{text: "pod cpu > 500 for 2m 3m", wantErr: "invalid duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rule, err := ParseRule(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseRule() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRule() error = %v", err)
+			}
+			tt.want.Text = strings.TrimSpace(tt.text)
+			if *rule != tt.want {
+				t.Errorf("ParseRule() = %+v, want %+v", *rule, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleEvaluate(t *testing.T) {
+	sample := Sample{
+		CPU:    Value{Usage: 450, Limit: 500, HasLimit: true},
+		Memory: Value{Usage: 100, Request: 64, HasRequest: true},
+	}
+	tests := []struct {
+		text      string
+		value     float64
+		threshold float64
+		matched   bool
+		ok        bool
+	}{
+		{text: "pod cpu > 0.8 * limit", value: 450, threshold: 400, matched: true, ok: true},
+		{text: "pod cpu% > 95", value: 90, threshold: 95, matched: false, ok: true},
+		{text: "pod memory >= 1.5 * request", value: 100, threshold: 96, matched: true, ok: true},
+		{text: "pod memory < 200", value: 100, threshold: 200, matched: true, ok: true},
+		// the sample has no values to compare
+		{text: "pod memory > limit", ok: false},
+		{text: "pod memory% > 90", ok: false},
+		{text: "pod cpu > request", ok: false},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRule(tt.text)
+		if err != nil {
+			t.Fatalf("ParseRule(%q) error = %v", tt.text, err)
+		}
+		value, threshold, matched, ok := rule.evaluate(sample)
+		if value != tt.value || threshold != tt.threshold || matched != tt.matched || ok != tt.ok {
+			t.Errorf("evaluate() of %q = %v, %v, %v, %v, want %v, %v, %v, %v",
+				tt.text, value, threshold, matched, ok, tt.value, tt.threshold, tt.matched, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ktop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ynqa/ktop/pkg/alert"
+	"github.com/ynqa/ktop/pkg/ui"
+)
+
+// SetAlertEngine evaluates the rules of the engine on each update.
+func (m *Monitor) SetAlertEngine(engine *alert.Engine) {
+	m.alertEngine = engine
+}
+
+func (m *Monitor) evaluateAlerts() {
+	if m.alertEngine == nil {
+		return
+	}
+	m.alertEngine.Evaluate(m.collectedAt, m.alertSamples())
+	m.updateAlerts()
+}
+
+// alertSamples returns the latest usages of the resources for each target of rules.
+func (m *Monitor) alertSamples() map[alert.Target][]alert.Sample {
+	samples := make(map[alert.Target][]alert.Sample)
+	for _, r := range m.resources {
+		cpu, _ := r.GetCpuUsage()
+		mem, _ := r.GetMemoryUsage()
+		samples[alert.ContainerTarget] = append(samples[alert.ContainerTarget], alert.Sample{
+			Key:    containerKey(r.GetNamespace(), r.GetPodName(), r.GetContainerName()),
+			CPU:    alertValue(r.HasUsage(), cpu, r.GetCpuLimits, r.GetCpuRequests),
+			Memory: alertValue(r.HasUsage(), mem, r.GetMemoryLimits, r.GetMemoryRequests),
+		})
+	}
+	for _, r := range m.summarizedResources {
+		cpu, _ := r.GetCpuUsage()
+		mem, _ := r.GetMemoryUsage()
+		samples[alert.PodTarget] = append(samples[alert.PodTarget], alert.Sample{
+			Key:    podKey(r.GetNamespace(), r.GetPodName()),
+			CPU:    alertValue(r.HasUsage(), cpu, r.GetCpuLimits, r.GetCpuRequests),
+			Memory: alertValue(r.HasUsage(), mem, r.GetMemoryLimits, r.GetMemoryRequests),
+		})
+	}
+	for _, r := range m.nodeResources {
+		cpu, _ := r.GetCpuUsage()
+		mem, _ := r.GetMemoryUsage()
+		samples[alert.NodeTarget] = append(samples[alert.NodeTarget], alert.Sample{
+			Key:    nodeKey(r.GetNodeName()),
+			CPU:    alertValue(r.HasUsage(), cpu, r.GetCpuAllocatable, nil),
+			Memory: alertValue(r.HasUsage(), mem, r.GetMemoryAllocatable, nil),
+		})
+	}
+	for _, r := range m.workloadResources {
+		cpu, _ := r.GetCpuUsage()
+		mem, _ := r.GetMemoryUsage()
+		samples[alert.WorkloadTarget] = append(samples[alert.WorkloadTarget], alert.Sample{
+			Key:    workloadKey(r.GetNamespace(), r.GetKind(), r.GetWorkloadName()),
+			CPU:    alertValue(r.HasUsage(), cpu, r.GetCpuLimits, r.GetCpuRequests),
+			Memory: alertValue(r.HasUsage(), mem, r.GetMemoryLimits, r.GetMemoryRequests),
+		})
+	}
+	for _, r := range m.namespaceResources {
+		cpu, _ := r.GetCpuUsage()
+		mem, _ := r.GetMemoryUsage()
+		samples[alert.NamespaceTarget] = append(samples[alert.NamespaceTarget], alert.Sample{
+			Key:    r.GetNamespace(),
+			CPU:    alertValue(r.HasUsage(), cpu, r.GetCpuLimitsQuota, r.GetCpuRequestsQuota),
+			Memory: alertValue(r.HasUsage(), mem, r.GetMemoryLimitsQuota, r.GetMemoryRequestsQuota),
+		})
+	}
+	return samples
+}
+
+// alertValue gets the limit and the request if the resource has them.
+func alertValue(hasUsage bool, usage float64, limit, request func() (float64, string, bool)) alert.Value {
+	v := alert.Value{HasUsage: hasUsage, Usage: usage}
+	if limit != nil {
+		v.Limit, _, v.HasLimit = limit()
+	}
+	if request != nil {
+		v.Request, _, v.HasRequest = request()
+	}
+	return v
+}
+
+// updateAlerts shows the firing alerts and the latest transitions.
+func (m *Monitor) updateAlerts() {
+	firing := m.alertEngine.Firing()
+	m.alerts.Title = alertsTitle
+	if len(firing) > 0 {
+		m.alerts.Title = fmt.Sprintf("⎈ Alerts (%v firing) ⎈", len(firing))
+	}
+
+	lines := make([]string, 0)
+	if err := m.alertEngine.Err(); err != nil {
+		lines = append(lines, fmt.Sprintf("[error: %v](fg:red)", err))
+	}
+	for _, event := range firing {
+		lines = append(lines, fmt.Sprintf("[since %v %v](fg:red)", event.StartsAt.Format("15:04:05"), event))
+	}
+	if len(firing) > 0 {
+		lines = append(lines, "")
+	}
+	for _, event := range m.alertEngine.Recent() {
+		lines = append(lines, fmt.Sprintf("%v %v", event.Time.Format("15:04:05"), event))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No alerts")
+	}
+	m.alerts.Text = strings.Join(lines, "\n")
+}
+
+func (m *Monitor) GetAlerts() *ui.Paragraph {
+	return m.alerts
+}
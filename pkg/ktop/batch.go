@@ -0,0 +1,105 @@
+package ktop
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+const (
+	// output formats
+	TextOutput  = ""
+	JSONOutput  = "json"
+	JSONLOutput = "jsonl"
+	CSVOutput   = "csv"
+)
+
+// Printer writes snapshots of the monitor to a writer.
+type Printer struct {
+	w           io.Writer
+	tableType   string
+	output      string
+	wroteHeader bool
+}
+
+func NewPrinter(w io.Writer, tableType, output string) (*Printer, error) {
+	switch tableType {
+	case resource.SummarizedType, resource.AllType, resource.NodeType, resource.WorkloadType, resource.NamespaceType:
+	default:
+		return nil, errors.Errorf("Unknown table type: %v", tableType)
+	}
+	switch output {
+	case TextOutput, JSONOutput, JSONLOutput, CSVOutput:
+	default:
+		return nil, errors.Errorf("Unknown output format: %v", output)
+	}
+	return &Printer{
+		w:         w,
+		tableType: tableType,
+		output:    output,
+	}, nil
+}
+
+// Print writes the latest snapshot collected by the monitor.
+func (p *Printer) Print(m *Monitor) error {
+	viewer := m.viewer(p.tableType)
+	viewer.SortRows()
+	switch p.output {
+	case JSONOutput:
+		_, records := viewer.GetRecords(m.collectedAt)
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	case JSONLOutput:
+		_, records := viewer.GetRecords(m.collectedAt)
+		encoder := json.NewEncoder(p.w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSVOutput:
+		header, records := viewer.GetRecords(m.collectedAt)
+		cw := csv.NewWriter(p.w)
+		if !p.wroteHeader {
+			cw.Write(header)
+			p.wroteHeader = true
+		}
+		for _, record := range records {
+			cw.Write(record.CSVRow())
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return p.printText(viewer)
+	}
+}
+
+func (p *Printer) printText(viewer resource.ResourceTableViewer) error {
+	title, header, _, rows := viewer.GetTableShape(image.Rectangle{})
+	if _, err := fmt.Fprintln(p.w, title); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(p.w)
+	return err
+}
@@ -0,0 +1,213 @@
+package ktop
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+const (
+	metricsPrefix = "ktop_"
+	millicores    = 1000.
+)
+
+// Exporter serves the latest snapshot of the monitor in the Prometheus text format.
+type Exporter struct {
+	monitor *Monitor
+
+	mu   sync.RWMutex
+	body []byte
+}
+
+func NewExporter(monitor *Monitor) *Exporter {
+	return &Exporter{
+		monitor: monitor,
+	}
+}
+
+// Update collects the latest resources and renders them as metrics.
+func (e *Exporter) Update() error {
+	if err := e.monitor.Collect(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := e.monitor.WriteMetrics(&buf); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.body = buf.Bytes()
+	return nil
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(e.body)
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+type gauge struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+func (g *gauge) add(value float64, labels ...string) {
+	g.samples = append(g.samples, sample{labels: labels, value: value})
+}
+
+func (g *gauge) write(w io.Writer) error {
+	if len(g.samples) == 0 {
+		return nil
+	}
+	name := metricsPrefix + g.name
+	if _, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v gauge\n", name, g.help, name); err != nil {
+		return err
+	}
+	for _, s := range g.samples {
+		pairs := make([]string, 0, len(s.labels)/2)
+		for i := 0; i+1 < len(s.labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", s.labels[i], labelValueEscaper.Replace(s.labels[i+1])))
+		}
+		if _, err := fmt.Fprintf(w, "%v{%v} %v\n",
+			name, strings.Join(pairs, ","), strconv.FormatFloat(s.value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMetrics writes the latest snapshot in the Prometheus text format.
+func (m *Monitor) WriteMetrics(w io.Writer) error {
+	var (
+		containerCPUUsage      = &gauge{name: "container_cpu_usage_cores", help: "CPU usage of the container in cores."}
+		containerCPULimit      = &gauge{name: "container_cpu_limit_cores", help: "CPU limit of the container in cores."}
+		containerCPURequest    = &gauge{name: "container_cpu_request_cores", help: "CPU request of the container in cores."}
+		containerCPURatio      = &gauge{name: "container_cpu_usage_limit_ratio", help: "Ratio of CPU usage to the limit of the container."}
+		containerMemoryUsage   = &gauge{name: "container_memory_usage_bytes", help: "Memory usage of the container in bytes."}
+		containerMemoryLimit   = &gauge{name: "container_memory_limit_bytes", help: "Memory limit of the container in bytes."}
+		containerMemoryRequest = &gauge{name: "container_memory_request_bytes", help: "Memory request of the container in bytes."}
+		containerMemoryRatio   = &gauge{name: "container_memory_usage_limit_ratio", help: "Ratio of memory usage to the limit of the container."}
+		podCPUUsage            = &gauge{name: "pod_cpu_usage_cores", help: "CPU usage of the pod in cores."}
+		podCPULimit            = &gauge{name: "pod_cpu_limit_cores", help: "Sum of CPU limits of the containers in the pod in cores."}
+		podCPURequest          = &gauge{name: "pod_cpu_request_cores", help: "Sum of CPU requests of the containers in the pod in cores."}
+		podMemoryUsage         = &gauge{name: "pod_memory_usage_bytes", help: "Memory usage of the pod in bytes."}
+		podMemoryLimit         = &gauge{name: "pod_memory_limit_bytes", help: "Sum of memory limits of the containers in the pod in bytes."}
+		podMemoryRequest       = &gauge{name: "pod_memory_request_bytes", help: "Sum of memory requests of the containers in the pod in bytes."}
+		nodeCPUUsage           = &gauge{name: "node_cpu_usage_cores", help: "CPU usage of the node in cores."}
+		nodeCPUCapacity        = &gauge{name: "node_cpu_capacity_cores", help: "CPU capacity of the node in cores."}
+		nodeCPUAllocatable     = &gauge{name: "node_cpu_allocatable_cores", help: "Allocatable CPU of the node in cores."}
+		nodeCPURatio           = &gauge{name: "node_cpu_usage_allocatable_ratio", help: "Ratio of CPU usage to the allocatable of the node."}
+		nodeMemoryUsage        = &gauge{name: "node_memory_usage_bytes", help: "Memory usage of the node in bytes."}
+		nodeMemoryCapacity     = &gauge{name: "node_memory_capacity_bytes", help: "Memory capacity of the node in bytes."}
+		nodeMemoryAllocatable  = &gauge{name: "node_memory_allocatable_bytes", help: "Allocatable memory of the node in bytes."}
+		nodeMemoryRatio        = &gauge{name: "node_memory_usage_allocatable_ratio", help: "Ratio of memory usage to the allocatable of the node."}
+	)
+
+	_, containers := m.viewer(resource.AllType).GetRecords(m.collectedAt)
+	for _, record := range containers {
+		c := record.(*resource.ContainerRecord)
+		labels := []string{"namespace", c.Namespace, "pod", c.Pod, "container", c.Container, "node", c.Node}
+		if c.CPUUsageMillicores != nil {
+			containerCPUUsage.add(float64(*c.CPUUsageMillicores)/millicores, labels...)
+		}
+		if c.MemoryUsageBytes != nil {
+			containerMemoryUsage.add(float64(*c.MemoryUsageBytes), labels...)
+		}
+		if c.CPULimitMillicores != nil {
+			containerCPULimit.add(float64(*c.CPULimitMillicores)/millicores, labels...)
+		}
+		if c.CPULimitPercentage != nil {
+			containerCPURatio.add(*c.CPULimitPercentage/100, labels...)
+		}
+		if c.CPURequestMillicores != nil {
+			containerCPURequest.add(float64(*c.CPURequestMillicores)/millicores, labels...)
+		}
+		if c.MemoryLimitBytes != nil {
+			containerMemoryLimit.add(float64(*c.MemoryLimitBytes), labels...)
+		}
+		if c.MemoryLimitPercentage != nil {
+			containerMemoryRatio.add(*c.MemoryLimitPercentage/100, labels...)
+		}
+		if c.MemoryRequestBytes != nil {
+			containerMemoryRequest.add(float64(*c.MemoryRequestBytes), labels...)
+		}
+	}
+
+	_, pods := m.viewer(resource.SummarizedType).GetRecords(m.collectedAt)
+	for _, record := range pods {
+		p := record.(*resource.PodRecord)
+		labels := []string{"namespace", p.Namespace, "pod", p.Pod, "node", p.Node}
+		if p.CPUUsageMillicores != nil {
+			podCPUUsage.add(float64(*p.CPUUsageMillicores)/millicores, labels...)
+		}
+		if p.MemoryUsageBytes != nil {
+			podMemoryUsage.add(float64(*p.MemoryUsageBytes), labels...)
+		}
+		// the sums are reported only if all containers define them
+		if p.CPULimitMillicores != nil {
+			podCPULimit.add(float64(*p.CPULimitMillicores)/millicores, labels...)
+		}
+		if p.CPURequestMillicores != nil {
+			podCPURequest.add(float64(*p.CPURequestMillicores)/millicores, labels...)
+		}
+		if p.MemoryLimitBytes != nil {
+			podMemoryLimit.add(float64(*p.MemoryLimitBytes), labels...)
+		}
+		if p.MemoryRequestBytes != nil {
+			podMemoryRequest.add(float64(*p.MemoryRequestBytes), labels...)
+		}
+	}
+
+	_, nodes := m.viewer(resource.NodeType).GetRecords(m.collectedAt)
+	for _, record := range nodes {
+		n := record.(*resource.NodeRecord)
+		labels := []string{"node", n.Node}
+		nodeCPUCapacity.add(float64(n.CPUCapacityMillicores)/millicores, labels...)
+		nodeCPUAllocatable.add(float64(n.CPUAllocatableMillicores)/millicores, labels...)
+		nodeMemoryCapacity.add(float64(n.MemoryCapacityBytes), labels...)
+		nodeMemoryAllocatable.add(float64(n.MemoryAllocatableBytes), labels...)
+		if n.CPUUsageMillicores != nil {
+			nodeCPUUsage.add(float64(*n.CPUUsageMillicores)/millicores, labels...)
+		}
+		if n.CPUUsagePercentage != nil {
+			nodeCPURatio.add(*n.CPUUsagePercentage/100, labels...)
+		}
+		if n.MemoryUsageBytes != nil {
+			nodeMemoryUsage.add(float64(*n.MemoryUsageBytes), labels...)
+		}
+		if n.MemoryUsagePercentage != nil {
+			nodeMemoryRatio.add(*n.MemoryUsagePercentage/100, labels...)
+		}
+	}
+
+	for _, g := range []*gauge{
+		containerCPUUsage, containerCPULimit, containerCPURequest, containerCPURatio,
+		containerMemoryUsage, containerMemoryLimit, containerMemoryRequest, containerMemoryRatio,
+		podCPUUsage, podCPULimit, podCPURequest,
+		podMemoryUsage, podMemoryLimit, podMemoryRequest,
+		nodeCPUUsage, nodeCPUCapacity, nodeCPUAllocatable, nodeCPURatio,
+		nodeMemoryUsage, nodeMemoryCapacity, nodeMemoryAllocatable, nodeMemoryRatio,
+	} {
+		if err := g.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package ktop
+
+import (
+	"fmt"
+
+	"github.com/ynqa/ktop/pkg/resource"
+	"github.com/ynqa/ktop/pkg/ui"
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+// filter narrows the rows of tables by their names.
+type filter struct {
+	name    string
+	mode    MatchMode
+	query   string
+	matcher Matcher
+	// error of the latest query, the previous matcher is kept while it is set
+	err error
+}
+
+func newFilter(name, query string) *filter {
+	f := &filter{
+		name:    name,
+		matcher: matchAll{},
+	}
+	f.set(RegexpMatch, query)
+	return f
+}
+
+func (f *filter) set(mode MatchMode, query string) {
+	f.mode = mode
+	f.query = query
+	matcher, err := NewMatcher(mode, query)
+	if err != nil {
+		f.err = err
+		return
+	}
+	f.matcher = matcher
+	f.err = nil
+}
+
+func (f *filter) match(name string) bool {
+	return f.matcher.MatchString(name)
+}
+
+type matchAll struct{}
+
+func (matchAll) MatchString(string) bool { return true }
+func (matchAll) String() string          { return "" }
+
+// activeFilter returns the filter edited for the current table.
+func (m *Monitor) activeFilter() *filter {
+	switch m.tableType() {
+	case resource.AllType:
+		return m.containerFilter
+	case resource.NodeType:
+		return m.nodeFilter
+	case resource.WorkloadType:
+		return m.workloadFilter
+	case resource.NamespaceType:
+		return m.namespaceFilter
+	default:
+		return m.podFilter
+	}
+}
+
+func (m *Monitor) filterSummarizedResources() []*resource.SummarizedResource {
+	filtered := make([]*resource.SummarizedResource, 0, len(m.summarizedResources))
+	for _, r := range m.summarizedResources {
+		if m.namespaceFilter.match(r.GetNamespace()) && m.podFilter.match(r.GetPodName()) &&
+			m.inScope(r.GetNamespace(), r.GetPodName(), r.GetNodeName()) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func (m *Monitor) filterResources() []*resource.Resource {
+	filtered := make([]*resource.Resource, 0, len(m.resources))
+	for _, r := range m.resources {
+		if m.namespaceFilter.match(r.GetNamespace()) &&
+			m.podFilter.match(r.GetPodName()) &&
+			m.containerFilter.match(r.GetContainerName()) &&
+			m.inScope(r.GetNamespace(), r.GetPodName(), r.GetNodeName()) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func (m *Monitor) filterNodeResources() []*resource.NodeResource {
+	filtered := make([]*resource.NodeResource, 0, len(m.nodeResources))
+	for _, r := range m.nodeResources {
+		if m.nodeFilter.match(r.GetNodeName()) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func (m *Monitor) filterWorkloadResources() []*resource.WorkloadResource {
+	filtered := make([]*resource.WorkloadResource, 0, len(m.workloadResources))
+	for _, r := range m.workloadResources {
+		if m.namespaceFilter.match(r.GetNamespace()) && m.workloadFilter.match(r.GetWorkloadName()) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func (m *Monitor) filterNamespaceResources() []*resource.NamespaceResource {
+	filtered := make([]*resource.NamespaceResource, 0, len(m.namespaceResources))
+	for _, r := range m.namespaceResources {
+		if m.namespaceFilter.match(r.GetNamespace()) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// FilterError returns the first error of the queries.
+func (m *Monitor) FilterError() error {
+	for _, f := range []*filter{m.namespaceFilter, m.podFilter, m.containerFilter, m.nodeFilter} {
+		if f.err != nil {
+			return f.err
+		}
+	}
+	return nil
+}
+
+func (m *Monitor) IsFiltering() bool {
+	return m.editingFilter != nil
+}
+
+func (m *Monitor) GetFilterPrompt() *ui.Prompt {
+	return m.filterPrompt
+}
+
+// OpenFilter starts editing the query of the current table.
+func (m *Monitor) OpenFilter() {
+	m.openFilter(m.activeFilter())
+}
+
+// OpenNamespaceFilter starts editing the query of namespaces for the pod tables.
+func (m *Monitor) OpenNamespaceFilter() {
+	if m.tableType() == resource.NodeType {
+		return
+	}
+	m.openFilter(m.namespaceFilter)
+}
+
+func (m *Monitor) openFilter(f *filter) {
+	m.editingFilter = f
+	m.filterBackupMode = f.mode
+	m.filterBackupQuery = f.query
+	m.filterPrompt.Text = f.query
+	m.updateFilterPrompt()
+}
+
+// CloseFilter stops editing the query, restoring the previous query if canceled.
+func (m *Monitor) CloseFilter(cancel bool) {
+	if m.editingFilter == nil {
+		return
+	}
+	if cancel {
+		m.editingFilter.set(m.filterBackupMode, m.filterBackupQuery)
+	}
+	m.editingFilter = nil
+	m.refresh()
+}
+
+func (m *Monitor) InsertFilter(s string) {
+	m.filterPrompt.Insert(s)
+	m.applyFilter()
+}
+
+func (m *Monitor) BackspaceFilter() {
+	m.filterPrompt.Backspace()
+	m.applyFilter()
+}
+
+// CycleFilterMode switches the way to match the query.
+func (m *Monitor) CycleFilterMode() {
+	m.editingFilter.mode = m.editingFilter.mode.Next()
+	m.applyFilter()
+}
+
+func (m *Monitor) applyFilter() {
+	m.editingFilter.set(m.editingFilter.mode, m.filterPrompt.Text)
+	m.updateFilterPrompt()
+	m.refresh()
+}
+
+func (m *Monitor) updateFilterPrompt() {
+	f := m.editingFilter
+	m.filterPrompt.Label = fmt.Sprintf("%v query (%v): ", f.name, f.mode)
+	m.filterPrompt.Message = ""
+	if f.err != nil {
+		m.filterPrompt.Message = f.err.Error()
+	}
+}
@@ -0,0 +1,91 @@
+package ktop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gizak/termui/v3"
+
+	"github.com/ynqa/ktop/pkg/resource"
+	"github.com/ynqa/ktop/pkg/ui"
+)
+
+var (
+	// ticks on the Y-axis in the units of the values
+	cpuTickFormatter        = func(v float64) string { return fmt.Sprintf("%vm", int64(v)) }
+	memoryTickFormatter     = func(v float64) string { return fmt.Sprintf("%vMi", int64(v)) }
+	percentageTickFormatter = func(v float64) string { return fmt.Sprintf("%v%%", int64(v)) }
+)
+
+const (
+	cpuGraphTitle    = "CPU Usage"
+	memoryGraphTitle = "Memory Usage"
+	// the Y-axis of the node table is percentages of the allocatable
+	percentageGraphMax = 100
+)
+
+// graphLines collects the reference lines of the graph.
+type graphLines []ui.ReferenceLine
+
+// add appends the line if the value is defined.
+func (l graphLines) add(label string, color termui.Color, val float64, str string, ok bool) graphLines {
+	if !ok {
+		return l
+	}
+	return append(l, ui.ReferenceLine{
+		Value: val,
+		Label: fmt.Sprintf("%v: %v", label, str),
+		Color: color,
+	})
+}
+
+func setGraph(g *ui.Graph, header string, data []float64, labelData string, formatter func(float64) string, lines graphLines) {
+	g.LabelHeader = header
+	g.Series = []ui.Series{
+		{Label: labelData, Data: data, Color: graphDataColor},
+	}
+	g.YTickFormatter = formatter
+	g.ReferenceLines = lines
+}
+
+// SetGraphScale sets the scale of the Y-axis of the graphs.
+// cpuMax and memMax, in millicores and Mi, are used in the fixed mode.
+func (m *Monitor) SetGraphScale(mode ui.ScaleMode, cpuMax, memMax float64) {
+	m.graphScale = mode
+	m.cpuGraphMax = cpuMax
+	m.memGraphMax = memMax
+	m.updateGraphScale()
+}
+
+// SetGraphInterval describes the X-axis of the graphs in the interval between refreshes.
+func (m *Monitor) SetGraphInterval(interval time.Duration) {
+	m.cpuGraph.Interval = interval
+	m.memGraph.Interval = interval
+}
+
+// CycleGraphScale switches the scale of the Y-axis to the next mode.
+func (m *Monitor) CycleGraphScale() {
+	m.graphScale = m.graphScale.Next()
+	m.updateGraphScale()
+}
+
+func (m *Monitor) updateGraphScale() {
+	mode := m.currentGraphMode()
+	cpuMax, memMax := m.cpuGraphMax, m.memGraphMax
+	if mode != GraphUsage {
+		cpuMax, memMax = m.statsGraphMax(mode), m.statsGraphMax(mode)
+	} else if m.tableType() == resource.NodeType {
+		cpuMax, memMax = percentageGraphMax, percentageGraphMax
+	}
+	cpuTitle, memTitle := m.graphTitles(mode)
+	m.cpuGraph.ScaleMode = m.graphScale
+	m.cpuGraph.FixedMax = cpuMax
+	m.cpuGraph.Title = graphTitle(cpuTitle, m.graphScale)
+	m.memGraph.ScaleMode = m.graphScale
+	m.memGraph.FixedMax = memMax
+	m.memGraph.Title = graphTitle(memTitle, m.graphScale)
+}
+
+func graphTitle(title string, mode ui.ScaleMode) string {
+	return fmt.Sprintf("⎈ %v (scale: %v) ⎈", title, mode)
+}
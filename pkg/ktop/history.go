@@ -0,0 +1,165 @@
+package ktop
+
+import (
+	"math"
+)
+
+const (
+	// number of samples kept for each resource
+	defaultHistorySize = 300
+)
+
+// ringBuffer keeps the latest samples up to its capacity.
+type ringBuffer struct {
+	data   []float64
+	start  int
+	length int
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{
+		data: make([]float64, capacity),
+	}
+}
+
+func (r *ringBuffer) push(val float64) {
+	capacity := len(r.data)
+	if capacity == 0 {
+		return
+	}
+	if r.length < capacity {
+		r.data[(r.start+r.length)%capacity] = val
+		r.length++
+		return
+	}
+	r.data[r.start] = val
+	r.start = (r.start + 1) % capacity
+}
+
+// setLatest replaces the latest sample.
+func (r *ringBuffer) setLatest(val float64) {
+	if r.length == 0 {
+		return
+	}
+	r.data[(r.start+r.length-1)%len(r.data)] = val
+}
+
+// values returns a copy of the samples from oldest to latest.
+func (r *ringBuffer) values() []float64 {
+	vals := make([]float64, r.length)
+	for i := 0; i < r.length; i++ {
+		vals[i] = r.data[(r.start+i)%len(r.data)]
+	}
+	return vals
+}
+
+type timeSeries struct {
+	cpu *ringBuffer
+	mem *ringBuffer
+	// stats of the kubelets, which are NaN at the ticks without them
+	// to keep aligned with the usages
+	stats    [numStatsKinds]*ringBuffer
+	lastTick uint64
+}
+
+func (t *timeSeries) CPU() []float64 {
+	if t == nil {
+		return make([]float64, 0)
+	}
+	return t.cpu.values()
+}
+
+func (t *timeSeries) Memory() []float64 {
+	if t == nil {
+		return make([]float64, 0)
+	}
+	return t.mem.values()
+}
+
+// Stats returns the samples of the stats of the kind.
+func (t *timeSeries) Stats(kind statsKind) []float64 {
+	if t == nil {
+		return make([]float64, 0)
+	}
+	return t.stats[kind].values()
+}
+
+// history stores time series of usages for each resource
+// and forgets the resources which are no longer observed.
+type history struct {
+	size   int
+	tick   uint64
+	series map[string]*timeSeries
+}
+
+func newHistory(size int) *history {
+	return &history{
+		size:   size,
+		series: make(map[string]*timeSeries),
+	}
+}
+
+// begin starts recording samples for a new tick.
+func (h *history) begin() {
+	h.tick++
+}
+
+func (h *history) record(key string, cpu, mem float64) {
+	ts, ok := h.series[key]
+	if !ok {
+		ts = &timeSeries{
+			cpu: newRingBuffer(h.size),
+			mem: newRingBuffer(h.size),
+		}
+		for kind := range ts.stats {
+			ts.stats[kind] = newRingBuffer(h.size)
+		}
+		h.series[key] = ts
+	}
+	ts.cpu.push(cpu)
+	ts.mem.push(mem)
+	// filled by recordStats if reported
+	for _, stats := range ts.stats {
+		stats.push(math.NaN())
+	}
+	ts.lastTick = h.tick
+}
+
+// recordStats sets the stats of the series recorded at the current tick,
+// except for the NaN ones which are not reported.
+func (h *history) recordStats(key string, stats [numStatsKinds]float64) {
+	ts, ok := h.series[key]
+	if !ok || ts.lastTick != h.tick {
+		return
+	}
+	for kind, val := range stats {
+		if !math.IsNaN(val) {
+			ts.stats[kind].setLatest(val)
+		}
+	}
+}
+
+// commit drops the series which were not recorded at the current tick.
+func (h *history) commit() {
+	for key, ts := range h.series {
+		if ts.lastTick != h.tick {
+			delete(h.series, key)
+		}
+	}
+}
+
+func (h *history) get(key string) *timeSeries {
+	return h.series[key]
+}
+
+func podKey(namespace, pod string) string {
+	return namespace + "/" + pod
+}
+
+func containerKey(namespace, pod, container string) string {
+	return namespace + "/" + pod + "/" + container
+}
+
+func nodeKey(node string) string {
+	return node
+}
@@ -0,0 +1,143 @@
+package ktop
+
+import (
+	"math"
+	"testing"
+)
+
+// equalSamples compares the samples, regarding NaN as equal to NaN.
+func equalSamples(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.IsNaN(want[i]) {
+			if !math.IsNaN(got[i]) {
+				return false
+			}
+		} else if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func float64Ptr(val float64) *float64 {
+	return &val
+}
+
+func TestRingBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		pushes   []float64
+		latest   *float64
+		want     []float64
+	}{
+		{name: "empty", capacity: 3, want: []float64{}},
+		{name: "partial", capacity: 3, pushes: []float64{1, 2}, want: []float64{1, 2}},
+		{name: "full", capacity: 3, pushes: []float64{1, 2, 3}, want: []float64{1, 2, 3}},
+		{name: "wraparound", capacity: 3, pushes: []float64{1, 2, 3, 4, 5}, want: []float64{3, 4, 5}},
+		{name: "wraparound twice", capacity: 3, pushes: []float64{1, 2, 3, 4, 5, 6, 7}, want: []float64{5, 6, 7}},
+		{name: "latest after wraparound", capacity: 3, pushes: []float64{1, 2, 3, 4}, latest: float64Ptr(9), want: []float64{2, 3, 9}},
+		{name: "latest of empty", capacity: 3, latest: float64Ptr(9), want: []float64{}},
+		{name: "no capacity", capacity: 0, pushes: []float64{1}, want: []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRingBuffer(tt.capacity)
+			for _, val := range tt.pushes {
+				r.push(val)
+			}
+			if tt.latest != nil {
+				r.setLatest(*tt.latest)
+			}
+			if got := r.values(); !equalSamples(got, tt.want) {
+				t.Errorf("values() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	nan := math.NaN()
+	h := newHistory(3)
+
+	// a pod without metrics at the second tick leaves a gap
+	ticks := []struct {
+		cpu, mem float64
+	}{
+		{cpu: 1, mem: 10},
+		{cpu: nan, mem: nan},
+		{cpu: 3, mem: 30},
+		{cpu: 4, mem: 40},
+	}
+	for _, tick := range ticks {
+		h.begin()
+		h.record("default/web", tick.cpu, tick.mem)
+		h.commit()
+	}
+	ts := h.get("default/web")
+	if got, want := ts.CPU(), []float64{nan, 3, 4}; !equalSamples(got, want) {
+		t.Errorf("CPU() = %v, want %v", got, want)
+	}
+	if got, want := ts.Memory(), []float64{nan, 30, 40}; !equalSamples(got, want) {
+		t.Errorf("Memory() = %v, want %v", got, want)
+	}
+
+	// the series which are not recorded at a tick are dropped
+	h.begin()
+	h.record("default/db", 5, 50)
+	h.commit()
+	if ts := h.get("default/web"); ts != nil {
+		t.Errorf("get(default/web) = %v, want dropped", ts)
+	}
+	if got, want := h.get("default/db").CPU(), []float64{5}; !equalSamples(got, want) {
+		t.Errorf("CPU() of the new series = %v, want %v", got, want)
+	}
+	if got := h.get("default/web").CPU(); len(got) != 0 {
+		t.Errorf("CPU() of the dropped series = %v, want empty", got)
+	}
+}
+
+func TestHistoryRecordStats(t *testing.T) {
+	nan := math.NaN()
+	h := newHistory(3)
+
+	h.begin()
+	h.record("node-1", 1, 10)
+	h.record("node-2", 2, 20)
+	h.recordStats("node-1", [numStatsKinds]float64{rxStats: 100, txStats: 200, fsStats: nan, inodesStats: nan})
+	// the series which is not recorded yet is ignored
+	h.recordStats("node-3", [numStatsKinds]float64{rxStats: 300})
+	h.commit()
+
+	h.begin()
+	h.record("node-1", 1, 10)
+	// the series which is not recorded at the tick is stale,
+	// and its latest sample of the previous tick is kept
+	h.recordStats("node-2", [numStatsKinds]float64{rxStats: 400})
+	if got, want := h.get("node-2").Stats(rxStats), []float64{nan}; !equalSamples(got, want) {
+		t.Errorf("Stats(rxStats) of the stale series = %v, want %v", got, want)
+	}
+	h.commit()
+
+	if ts := h.get("node-3"); ts != nil {
+		t.Errorf("get(node-3) = %v, want nil", ts)
+	}
+	if ts := h.get("node-2"); ts != nil {
+		t.Errorf("get(node-2) = %v, want dropped", ts)
+	}
+	// the stats are padded with NaN at the ticks without them
+	ts := h.get("node-1")
+	for kind, want := range [numStatsKinds][]float64{
+		rxStats:     {100, nan},
+		txStats:     {200, nan},
+		fsStats:     {nan, nan},
+		inodesStats: {nan, nan},
+	} {
+		if got := ts.Stats(statsKind(kind)); !equalSamples(got, want) {
+			t.Errorf("Stats(%v) = %v, want %v", kind, got, want)
+		}
+	}
+}
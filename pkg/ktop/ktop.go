@@ -3,17 +3,17 @@ package ktop
 import (
 	"container/ring"
 	"fmt"
-	"regexp"
-	"sync"
+	"io"
+	"math"
+	"time"
 
 	"github.com/gizak/termui/v3"
-	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	kr "k8s.io/apimachinery/pkg/api/resource"
-	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/metrics"
 
-	"github.com/ynqa/ktop/pkg/kube"
+	"github.com/ynqa/ktop/pkg/alert"
 	"github.com/ynqa/ktop/pkg/resource"
 	"github.com/ynqa/ktop/pkg/ui"
 	. "github.com/ynqa/ktop/pkg/util"
@@ -22,43 +22,126 @@ import (
 var (
 	// style
 	titleStyle = termui.NewStyle(termui.ColorWhite, termui.ColorClear, termui.ModifierBold)
+
+	// titles of the panes under the table
+	logsTitle       = "⎈ Logs ⎈"
+	nodeDetailTitle = "⎈ Node Detail ⎈"
+	alertsTitle     = "⎈ Alerts ⎈"
 )
 
 const (
 	// label names
-	containerLimitLabel  = "ContainerLimits"
-	nodeAllocatableLabel = "NodeAllocatable"
+	containerRequestLabel = "ContainerRequests"
+	containerLimitLabel   = "ContainerLimits"
+	podRequestLabel       = "PodRequests"
+	podLimitLabel         = "PodLimits"
+	nodeAllocatableLabel  = "NodeAllocatable"
 
 	// colors
-	borderColor         = termui.ColorBlue
-	selectedTableColor  = termui.ColorYellow
-	graphLabelNameColor = termui.ColorWhite
-	graphLimitColor     = termui.ColorWhite
-	graphDataColor      = termui.ColorGreen
+	borderColor           = termui.ColorBlue
+	selectedTableColor    = termui.ColorYellow
+	graphLabelNameColor   = termui.ColorWhite
+	graphLimitColor       = termui.ColorWhite
+	graphRequestColor     = termui.ColorCyan
+	graphAllocatableColor = termui.ColorMagenta
+	graphDataColor        = termui.ColorGreen
+	warningRowColor       = termui.Color(214)
+	errorRowColor         = termui.ColorRed
 )
 
 type Monitor struct {
-	*kube.KubeClients
+	source Source
 
 	logs            *ui.Paragraph
+	alerts          *ui.Paragraph
 	table           *ui.Table
 	tableTypeCircle *ring.Ring
+	// tables drilled down from the table of the circle
+	views []*view
+	// resource of the selected row
+	selected interface{}
+	// rows overlaid on the graphs
+	marks []*mark
+	// usage drawn as the trend of each row
+	sparklineMode SparklineMode
+	// usages above them are highlighted
+	thresholds resource.Thresholds
+
+	// evaluates alert rules on each update, or nil
+	alertEngine *alert.Engine
+
+	sortType  resource.SortType
+	sortOrder resource.SortOrder
 
 	cpuGraph *ui.Graph
 	memGraph *ui.Graph
-
-	podQuery       *regexp.Regexp
-	containerQuery *regexp.Regexp
-	nodeQuery      *regexp.Regexp
+	// scale of the Y-axis of the graphs
+	graphScale  ui.ScaleMode
+	cpuGraphMax float64
+	memGraphMax float64
+	// stats of the kubelets drawn instead of the usages
+	graphMode GraphMode
+
+	namespaceFilter *filter
+	podFilter       *filter
+	containerFilter *filter
+	nodeFilter      *filter
+	workloadFilter  *filter
+
+	// prompt to edit a filter
+	filterPrompt      *ui.Prompt
+	editingFilter     *filter
+	filterBackupMode  MatchMode
+	filterBackupQuery string
+
+	// logs of the selected pod
+	logStream    *logStream
+	logStreamKey string
+
+	// time series of usages
+	podHistory       *history
+	containerHistory *history
+	nodeHistory      *history
+	workloadHistory  *history
+	namespaceHistory *history
+
+	// whether the kubelets report the stats
+	withStats bool
+	// previous traffic of the networks of the pods and the nodes
+	networkSamples map[string]networkSample
+
+	// failure of the last update, which is shown on the table
+	updateErr error
+
+	// latest snapshot
+	collectedAt         time.Time
+	nodeList            *corev1.NodeList
+	resources           []*resource.Resource
+	summarizedResources []*resource.SummarizedResource
+	nodeResources       []*resource.NodeResource
+	workloadResources   []*resource.WorkloadResource
+	namespaceResources  []*resource.NamespaceResource
+	// why the workload table is unavailable, e.g. forbidden
+	workloadsErr string
+	// why the quotas of the namespace table are unavailable
+	quotasErr string
 }
 
-func NewMonitor(kubeclients *kube.KubeClients, podQuery, containerQuery, nodeQuery *regexp.Regexp) *Monitor {
+func NewMonitor(source Source, namespaceQuery, podQuery, containerQuery, nodeQuery string) *Monitor {
 	monitor := &Monitor{
-		KubeClients:     kubeclients,
-		tableTypeCircle: resource.TableTypeCircle(),
-		podQuery:        podQuery,
-		containerQuery:  containerQuery,
-		nodeQuery:       nodeQuery,
+		source:           source,
+		tableTypeCircle:  resource.TableTypeCircle(),
+		namespaceFilter:  newFilter("namespace", namespaceQuery),
+		podFilter:        newFilter("pod", podQuery),
+		containerFilter:  newFilter("container", containerQuery),
+		nodeFilter:       newFilter("node", nodeQuery),
+		workloadFilter:   newFilter("workload", ".*"),
+		filterPrompt:     ui.NewPrompt(),
+		podHistory:       newHistory(defaultHistorySize),
+		containerHistory: newHistory(defaultHistorySize),
+		nodeHistory:      newHistory(defaultHistorySize),
+		workloadHistory:  newHistory(defaultHistorySize),
+		namespaceHistory: newHistory(defaultHistorySize),
 	}
 
 	// table for resources
@@ -70,32 +153,36 @@ func NewMonitor(kubeclients *kube.KubeClients, podQuery, containerQuery, nodeQue
 
 	// logs of pod
 	logs := ui.NewParagraph()
-	logs.Title = "⎈ Logs ⎈"
+	logs.Title = logsTitle
 	logs.TitleStyle = titleStyle
 	logs.Text = `Loading...`
 	logs.BorderStyle = termui.NewStyle(borderColor)
 	logs.TextStyle = termui.NewStyle(termui.Color(244), termui.ColorClear)
 
+	// alerts fired by the rules
+	alerts := ui.NewParagraph()
+	alerts.Title = alertsTitle
+	alerts.TitleStyle = titleStyle
+	alerts.BorderStyle = termui.NewStyle(borderColor)
+	alerts.TextStyle = termui.NewStyle(termui.Color(244), termui.ColorClear)
+
 	// graph for cpu
 	cpu := ui.NewGraph()
-	cpu.Title = "⎈ CPU Usage ⎈"
+	cpu.Title = graphTitle(cpuGraphTitle, ui.ScaleFitData)
 	cpu.TitleStyle = titleStyle
 	cpu.BorderStyle = termui.NewStyle(borderColor)
 	cpu.LabelNameColor = graphLabelNameColor
-	cpu.DataColor = graphDataColor
-	cpu.LimitColor = graphLimitColor
 
 	// graph for memory
 	mem := ui.NewGraph()
-	mem.Title = "⎈ Memory Usage ⎈"
+	mem.Title = graphTitle(memoryGraphTitle, ui.ScaleFitData)
 	mem.TitleStyle = titleStyle
 	mem.BorderStyle = termui.NewStyle(borderColor)
 	mem.LabelNameColor = graphLabelNameColor
-	mem.DataColor = graphDataColor
-	mem.LimitColor = graphLimitColor
 
 	monitor.table = table
 	monitor.logs = logs
+	monitor.alerts = alerts
 	monitor.cpuGraph = cpu
 	monitor.memGraph = mem
 	return monitor
@@ -107,15 +194,18 @@ func (m *Monitor) resetGraph() {
 }
 
 func (m *Monitor) resetTable() {
+	m.marks = nil
+	m.resetSparklines()
 	m.table.Reset(resource.ResetTableShapeFrom(
-		m.tableTypeCircle.Value.(string),
-		m.table.Inner,
+		m.tableType(),
+		m.table.ColumnsRect(),
 	))
 }
 
 func (m *Monitor) ScrollDown() {
 	m.scrollDown()
 	m.resetGraph()
+	m.refresh()
 }
 
 func (m *Monitor) scrollDown() {
@@ -125,6 +215,7 @@ func (m *Monitor) scrollDown() {
 func (m *Monitor) ScrollUp() {
 	m.scrollUp()
 	m.resetGraph()
+	m.refresh()
 }
 
 func (m *Monitor) scrollUp() {
@@ -135,16 +226,63 @@ func (m *Monitor) Rotate() {
 	m.rotate(1)
 	m.resetGraph()
 	m.resetTable()
+	m.resetLogs()
+	m.refresh()
 }
 
 func (m *Monitor) ReverseRotate() {
 	m.rotate(-1)
 	m.resetGraph()
 	m.resetTable()
+	m.resetLogs()
+	m.refresh()
+}
+
+// resetLogs clears the pane under the table which is switched by the table.
+func (m *Monitor) resetLogs() {
+	m.stopLogs()
+	m.logs.Title = logsTitle
+	m.logs.Text = ""
 }
 
 func (m *Monitor) rotate(i int) {
+	m.views = nil
 	m.tableTypeCircle = m.tableTypeCircle.Move(i)
+	m.keepSortType()
+}
+
+// keepSortType keeps the sort type if the new table also has it.
+func (m *Monitor) keepSortType() {
+	for _, typ := range resource.SortTypesOf(m.tableType(), m.withStats) {
+		if typ == m.sortType {
+			return
+		}
+	}
+	m.sortType = resource.ByName
+	m.sortOrder = m.sortType.DefaultOrder()
+}
+
+// CycleSort switches the sort key to the next one available for the table.
+func (m *Monitor) CycleSort() {
+	sortTypes := resource.SortTypesOf(m.tableType(), m.withStats)
+	next := sortTypes[0]
+	for i, typ := range sortTypes {
+		if typ == m.sortType {
+			next = sortTypes[(i+1)%len(sortTypes)]
+			break
+		}
+	}
+	m.sortType = next
+	m.sortOrder = next.DefaultOrder()
+	m.resetGraph()
+	m.refresh()
+}
+
+// ReverseSort flips the sort order.
+func (m *Monitor) ReverseSort() {
+	m.sortOrder = m.sortOrder.Reverse()
+	m.resetGraph()
+	m.refresh()
 }
 
 func (m *Monitor) GetCPUGraph() *ui.Graph {
@@ -163,72 +301,121 @@ func (m *Monitor) GetLogs() *ui.Paragraph {
 	return m.logs
 }
 
-func (m *Monitor) Update() error {
-	nodeList, err := m.GetNodeList(labels.Everything())
+// Update collects the latest snapshot and redraws the dashboard.
+// The failure is shown on the table with the last snapshot kept,
+// so that the dashboard recovers on the next update.
+func (m *Monitor) Update() {
+	m.updateErr = m.Collect()
+	if m.updateErr == nil {
+		m.evaluateAlerts()
+	}
+	m.refresh()
+}
+
+// Collect fetches the latest resources and records their usages.
+func (m *Monitor) Collect() error {
+	snapshot, err := m.source.Snapshot()
 	if err != nil {
+		return err
+	}
+	if snapshot == nil {
 		return nil
 	}
 
-	var wg sync.WaitGroup
-	errCh := make(chan error, 2)
-	resourcesCh := make(chan []*resource.Resource, 1)
-	summarizedResourcesCh := make(chan []*resource.SummarizedResource, 1)
-	nodeResourcesCh := make(chan []*resource.NodeResource, 1)
+	resources, summarizedResources := m.fetchPodResources(snapshot)
+	nodeResources := m.fetchNodeResources(snapshot)
+	m.applyStats(snapshot, resources, summarizedResources, nodeResources)
+
+	m.collectedAt = snapshot.Time
+	m.nodeList = &corev1.NodeList{Items: snapshot.Nodes}
+	m.resources = resources
+	m.summarizedResources = summarizedResources
+	m.nodeResources = nodeResources
+	m.workloadResources = m.aggregateWorkloads(summarizedResources, snapshot.Workloads)
+	m.workloadsErr = snapshot.WorkloadsError
+	m.quotasErr = snapshot.QuotasError
+	m.namespaceResources = m.aggregateNamespaces(summarizedResources, snapshot.Quotas)
+	m.record()
+	return nil
+}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		resources, summarizedResources, err := m.fetchPodResources()
-		if err != nil {
-			errCh <- err
-			return
+// ResetHistory forgets the usages recorded so far,
+// e.g. when the snapshots are not continuous after seeking a session.
+func (m *Monitor) ResetHistory() {
+	for _, h := range []*history{m.podHistory, m.containerHistory, m.nodeHistory, m.workloadHistory, m.namespaceHistory} {
+		h.series = make(map[string]*timeSeries)
+	}
+	m.networkSamples = nil
+}
+
+// record appends the latest usages to the history of each resource.
+func (m *Monitor) record() {
+	// the resources without metrics are recorded as gaps rather than zeros
+	m.podHistory.begin()
+	for _, r := range m.summarizedResources {
+		cpu, mem := math.NaN(), math.NaN()
+		if r.HasUsage() {
+			cpu, _ = r.GetCpuUsage()
+			mem, _ = r.GetMemoryUsage()
 		}
-		resourcesCh <- resources
-		summarizedResourcesCh <- summarizedResources
-	}()
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		nodeResources, err := m.fetchNodeResources(nodeList)
-		if err != nil {
-			errCh <- err
-			return
+		m.podHistory.record(podKey(r.GetNamespace(), r.GetPodName()), cpu, mem)
+	}
+	m.podHistory.commit()
+
+	m.containerHistory.begin()
+	for _, r := range m.resources {
+		cpu, mem := math.NaN(), math.NaN()
+		if r.HasUsage() {
+			cpu, _ = r.GetCpuUsage()
+			mem, _ = r.GetMemoryUsage()
 		}
-		nodeResourcesCh <- nodeResources
-	}()
-
-	go func() {
-		wg.Wait()
-		close(errCh)
-		close(resourcesCh)
-		close(summarizedResourcesCh)
-		close(nodeResourcesCh)
-	}()
-
-	var mergedError error
-	for err := range errCh {
-		if mergedError == nil {
-			mergedError = errors.New(err.Error())
+		m.containerHistory.record(containerKey(r.GetNamespace(), r.GetPodName(), r.GetContainerName()), cpu, mem)
+	}
+	m.containerHistory.commit()
+
+	m.nodeHistory.begin()
+	for _, r := range m.nodeResources {
+		cpu, mem := math.NaN(), math.NaN()
+		if r.HasUsage() {
+			cpu, _ = r.GetCpuUsagePercentage()
+			mem, _ = r.GetMemoryUsagePercentage()
 		}
-		mergedError = errors.Wrap(mergedError, err.Error())
+		m.nodeHistory.record(nodeKey(r.GetNodeName()), cpu, mem)
 	}
-	if mergedError != nil {
-		return mergedError
+	m.nodeHistory.commit()
+
+	m.workloadHistory.begin()
+	for _, r := range m.workloadResources {
+		cpu, mem := math.NaN(), math.NaN()
+		if r.HasUsage() {
+			cpu, _ = r.GetCpuUsage()
+			mem, _ = r.GetMemoryUsage()
+		}
+		m.workloadHistory.record(workloadKey(r.GetNamespace(), r.GetKind(), r.GetWorkloadName()), cpu, mem)
 	}
-
-	resources, ok := <-resourcesCh
-	if !ok {
-		return errors.New("Failed to get resources")
+	m.workloadHistory.commit()
+
+	m.namespaceHistory.begin()
+	for _, r := range m.namespaceResources {
+		cpu, mem := math.NaN(), math.NaN()
+		if r.HasUsage() {
+			cpu, _ = r.GetCpuUsage()
+			mem, _ = r.GetMemoryUsage()
+		}
+		m.namespaceHistory.record(r.GetNamespace(), cpu, mem)
 	}
+	m.namespaceHistory.commit()
 
-	summarizedResources, ok := <-summarizedResourcesCh
-	if !ok {
-		return errors.New("Failed to get summarized resources")
-	}
+	m.recordStats()
+}
 
-	nodeResources, ok := <-nodeResourcesCh
-	if !ok {
-		return errors.New("Failed to get node resources")
+// refresh redraws the table and the graphs from the latest snapshot.
+func (m *Monitor) refresh() {
+	if m.nodeList == nil {
+		if m.updateErr != nil {
+			m.table.Title = fmt.Sprintf("⎈ %v ⎈", m.updateErr)
+		}
+		return
 	}
 
 	// temporary
@@ -238,188 +425,386 @@ func (m *Monitor) Update() error {
 		}
 	}()
 
-	switch m.tableTypeCircle.Value.(string) {
+	m.selected = nil
+	m.resetSparklines()
+	switch m.tableType() {
 	case resource.SummarizedType:
-		summarizedViewer := resource.AsSummarizedTableViewer(summarizedResources, resource.ByName)
+		summarizedResources := m.filterSummarizedResources()
+		summarizedViewer := resource.AsSummarizedTableViewer(summarizedResources, m.sortType, m.sortOrder)
 		summarizedViewer.SortRows()
 		m.updatePodTable(summarizedViewer)
+		m.updateMarks(len(summarizedResources), func(i int) interface{} { return summarizedResources[i] })
+		m.updateSparklines(len(summarizedResources), func(i int) interface{} { return summarizedResources[i] })
 		if len(summarizedResources) > 0 {
 			current := summarizedResources[m.table.SelectedRow]
-			if err := m.updateSummarizedGraph(nodeList, current); err != nil {
-				return err
-			}
+			m.selected = current
+			m.updateSummarizedGraph(m.nodeList, current)
 		}
 	case resource.AllType:
-		viewer := resource.AsAllTableViewer(resources, resource.ByName)
+		resources := m.filterResources()
+		viewer := resource.AsAllTableViewer(resources, m.sortType, m.sortOrder)
 		viewer.SortRows()
 		m.updatePodTable(viewer)
+		m.updateMarks(len(resources), func(i int) interface{} { return resources[i] })
+		m.updateSparklines(len(resources), func(i int) interface{} { return resources[i] })
 		if len(resources) > 0 {
 			current := resources[m.table.SelectedRow]
-			if err := m.updateAllGraph(nodeList, current); err != nil {
-				return err
-			}
+			m.selected = current
+			m.updateAllGraph(m.nodeList, current)
 		}
 	case resource.NodeType:
-		nodeViewer := resource.AsNodeTableViewer(nodeResources, resource.ByName)
+		nodeResources := m.filterNodeResources()
+		nodeViewer := resource.AsNodeTableViewer(nodeResources, m.sortType, m.sortOrder)
 		nodeViewer.SortRows()
 		m.updatePodTable(nodeViewer)
+		m.updateMarks(len(nodeResources), func(i int) interface{} { return nodeResources[i] })
+		m.updateSparklines(len(nodeResources), func(i int) interface{} { return nodeResources[i] })
 		if len(nodeResources) > 0 {
 			current := nodeResources[m.table.SelectedRow]
-			if err := m.updateNodeGraph(current); err != nil {
-				return err
-			}
+			m.selected = current
+			m.updateNodeGraph(current)
+			m.updateNodeDetail(current)
+		}
+	case resource.WorkloadType:
+		workloadResources := m.filterWorkloadResources()
+		workloadViewer := resource.AsWorkloadTableViewer(workloadResources, m.sortType, m.sortOrder)
+		workloadViewer.SortRows()
+		m.updatePodTable(workloadViewer)
+		if m.workloadsErr != "" {
+			m.table.Title = fmt.Sprintf("%v (unavailable: %v)", m.table.Title, m.workloadsErr)
+		}
+		m.updateMarks(len(workloadResources), func(i int) interface{} { return workloadResources[i] })
+		if len(workloadResources) > 0 {
+			current := workloadResources[m.table.SelectedRow]
+			m.selected = current
+			m.updateWorkloadGraph(current)
+		}
+	case resource.NamespaceType:
+		namespaceResources := m.filterNamespaceResources()
+		namespaceViewer := resource.AsNamespaceTableViewer(namespaceResources, m.sortType, m.sortOrder)
+		namespaceViewer.SortRows()
+		m.updatePodTable(namespaceViewer)
+		if m.quotasErr != "" {
+			m.table.Title = fmt.Sprintf("%v (quotas unavailable: %v)", m.table.Title, m.quotasErr)
+		}
+		m.updateMarks(len(namespaceResources), func(i int) interface{} { return namespaceResources[i] })
+		if len(namespaceResources) > 0 {
+			current := namespaceResources[m.table.SelectedRow]
+			m.selected = current
+			m.updateNamespaceGraph(current)
 		}
 	default:
 	}
-
-	return nil
+	// the pods on the node are listed only while its detail is shown
+	if node, ok := m.selected.(*resource.NodeResource); ok {
+		m.source.WatchNodePods(node.GetNodeName())
+	} else {
+		m.source.WatchNodePods("")
+	}
+	if m.currentGraphMode() != GraphUsage {
+		m.updateStatsGraph()
+	} else if len(m.marks) > 0 {
+		m.updateOverlayGraph()
+	}
+	m.updateGraphScale()
 }
 
-func (m *Monitor) fetchPodResources() ([]*resource.Resource, []*resource.SummarizedResource, error) {
-	podMetricsList, err := m.GetPodMetricsList(*m.Flags.Namespace, labels.Everything())
-	if err != nil {
-		return nil, nil, err
+func (m *Monitor) viewer(tableType string) resource.ResourceTableViewer {
+	switch tableType {
+	case resource.AllType:
+		return resource.AsAllTableViewer(m.filterResources(), m.sortType, m.sortOrder)
+	case resource.NodeType:
+		return resource.AsNodeTableViewer(m.filterNodeResources(), m.sortType, m.sortOrder)
+	case resource.WorkloadType:
+		return resource.AsWorkloadTableViewer(m.filterWorkloadResources(), m.sortType, m.sortOrder)
+	case resource.NamespaceType:
+		return resource.AsNamespaceTableViewer(m.filterNamespaceResources(), m.sortType, m.sortOrder)
+	default:
+		return resource.AsSummarizedTableViewer(m.filterSummarizedResources(), m.sortType, m.sortOrder)
 	}
-	podList, err := m.GetPodList(*m.Flags.Namespace, labels.Everything())
-	if err != nil {
-		return nil, nil, err
+}
+
+func (m *Monitor) fetchPodResources(snapshot *Snapshot) ([]*resource.Resource, []*resource.SummarizedResource) {
+	podMetricsIndex := make(map[string]*metrics.PodMetrics, len(snapshot.PodMetrics))
+	for i, podMetrics := range snapshot.PodMetrics {
+		podMetricsIndex[podKey(podMetrics.Namespace, podMetrics.Name)] = &snapshot.PodMetrics[i]
 	}
 
-	// collect resource list
+	// collect resource list from the pods,
+	// whose usages are left unset until metrics are reported
 	resources := make([]*resource.Resource, 0)
 	summarizedResources := make([]*resource.SummarizedResource, 0)
-	// filtered
-	for _, podMetrics := range FilterPodMetrics(m.podQuery, podMetricsList.Items) {
-		podName := podMetrics.Name
-		podLogs, err := m.GetPodLogs(*m.Flags.Namespace, podName)
-		if err != nil {
-			fmt.Print(err)
-		}
-		pod := FindPod(podName, podList.Items)
-		if pod == nil {
-			continue
+	for _, pod := range snapshot.Pods {
+		podMetrics, ok := podMetricsIndex[podKey(pod.Namespace, pod.Name)]
+		if !ok {
+			podMetrics = &metrics.PodMetrics{}
 		}
 		var cpu, mem kr.Quantity
-		// filtered
-		for _, containerMetrics := range FilterContainerMetrics(m.containerQuery, podMetrics.Containers) {
-			container := FindContainer(containerMetrics.Name, pod.Spec.Containers)
-			if container == nil {
-				continue
+		for _, container := range pod.Spec.Containers {
+			containerMetrics := FindContainerMetrics(container.Name, podMetrics.Containers)
+			if containerMetrics == nil {
+				containerMetrics = &metrics.ContainerMetrics{Name: container.Name}
+			} else {
+				cpu.Add(*containerMetrics.Usage.Cpu())
+				mem.Add(*containerMetrics.Usage.Memory())
 			}
-			containerResource := resource.NewResource(*pod, *container, containerMetrics)
+			containerResource := resource.NewResource(pod, container, *containerMetrics)
 			resources = append(resources, containerResource)
-			cpu.Add(*containerMetrics.Usage.Cpu())
-			mem.Add(*containerMetrics.Usage.Memory())
 		}
-		summarizedResource := resource.NewSummarizedResource(*pod,
-			corev1.ResourceList{
-				corev1.ResourceCPU:    cpu,
-				corev1.ResourceMemory: mem,
-			}, podLogs)
+		usage := corev1.ResourceList{}
+		if ok {
+			usage[corev1.ResourceCPU] = cpu
+			usage[corev1.ResourceMemory] = mem
+		}
+		summarizedResource := resource.NewSummarizedResource(pod, usage)
 		summarizedResources = append(summarizedResources, summarizedResource)
 	}
-	return resources, summarizedResources, nil
+	return resources, summarizedResources
 }
 
-func (m *Monitor) fetchNodeResources(nodeList *corev1.NodeList) ([]*resource.NodeResource, error) {
-	nodeMetricsList, err := m.GetNodeMetricsList(labels.Everything())
-	if err != nil {
-		return nil, err
+func (m *Monitor) fetchNodeResources(snapshot *Snapshot) []*resource.NodeResource {
+	nodeMetricsIndex := make(map[string]metrics.NodeMetrics, len(snapshot.NodeMetrics))
+	for _, nodeMetrics := range snapshot.NodeMetrics {
+		nodeMetricsIndex[nodeMetrics.Name] = nodeMetrics
 	}
+
+	// nodes without metrics, e.g. NotReady, are also listed
 	resources := make([]*resource.NodeResource, 0)
-	// filtered
-	for _, nodeMetrics := range FilterNodeMetrics(m.nodeQuery, nodeMetricsList.Items) {
-		node := FindNode(nodeMetrics.Name, nodeList.Items)
-		resources = append(resources, resource.NewNodeResource(*node, nodeMetrics))
+	for _, node := range snapshot.Nodes {
+		r := resource.NewNodeResource(node, nodeMetricsIndex[node.Name])
+		if node.Name == snapshot.DetailNode {
+			switch {
+			case snapshot.NodePodsError != "":
+				r.SetPodsError(snapshot.NodePodsError)
+			case snapshot.NodePods != nil:
+				r.SetPods(snapshot.NodePods.Items)
+			}
+		}
+		resources = append(resources, r)
 	}
-	return resources, nil
+	return resources
 }
 
 func (m *Monitor) updatePodTable(resources resource.ResourceTableViewer) {
-	m.table.Title, m.table.Header, m.table.ColumnWidths, m.table.Rows = resources.GetTableShape(m.table.Inner)
-}
-
-func (m *Monitor) updateSummarizedGraph(nodeList *corev1.NodeList, summarized *resource.SummarizedResource) error {
-	cpuUsage, cpuUsageStr := summarized.GetCpuUsage()
-	memUsage, memUsageStr := summarized.GetMemoryUsage()
-	node := FindNode(summarized.GetNodeName(), nodeList.Items)
-	limitCpu := GetResourceValue(node.Status.Allocatable, corev1.ResourceCPU)
-	limitCpuStr := GetResourceValueString(node.Status.Allocatable, corev1.ResourceCPU)
-	limitMemory := GetResourceValue(node.Status.Allocatable, corev1.ResourceMemory)
-	limitMemoryStr := GetResourceValueString(node.Status.Allocatable, corev1.ResourceMemory)
-
-	m.logs.Text = summarized.GetLogs()
-
-	m.cpuGraph.LabelHeader = fmt.Sprintf("Name: %v", summarized.GetPodName())
-	m.cpuGraph.Data = append(m.cpuGraph.Data, cpuUsage)
-	m.cpuGraph.LabelData = fmt.Sprintf("Usage: %v", cpuUsageStr)
-	m.cpuGraph.UpperLimit = limitCpu
-	m.cpuGraph.DrawUpperLimit = false
-	m.cpuGraph.LabelUpperLimit = fmt.Sprintf("%v: %v", nodeAllocatableLabel, limitCpuStr)
-
-	m.memGraph.LabelHeader = fmt.Sprintf("Name: %v", summarized.GetPodName())
-	m.memGraph.Data = append(m.memGraph.Data, memUsage)
-	m.memGraph.LabelData = fmt.Sprintf("Usage: %v", memUsageStr)
-	m.memGraph.UpperLimit = limitMemory
-	m.memGraph.DrawUpperLimit = false
-	m.memGraph.LabelUpperLimit = fmt.Sprintf("%v: %v", nodeAllocatableLabel, limitMemoryStr)
-	return nil
+	m.table.Title, m.table.Header, m.table.ColumnWidths, m.table.Rows = resources.GetTableShape(m.table.ColumnsRect())
+	// keep the cursor on the rows which may be narrowed by the filter
+	if m.table.SelectedRow >= len(m.table.Rows) {
+		m.table.SelectedRow = IntMax(0, len(m.table.Rows)-1)
+	}
+	if len(m.views) > 0 {
+		m.table.Title = fmt.Sprintf("%v (%v)", m.table.Title, m.breadcrumb())
+	}
+	if err := m.activeFilter().err; err != nil {
+		m.table.Title = fmt.Sprintf("%v (invalid query: %v)", m.table.Title, err)
+	}
+	if m.updateErr != nil {
+		m.table.Title = fmt.Sprintf("%v (%v)", m.table.Title, m.updateErr)
+	}
+	rowStates, cellStates := resources.GetRowStates(), resources.GetCellStates(m.thresholds)
+	if offending := countOffendingRows(cellStates); offending > 0 {
+		m.table.Title = fmt.Sprintf("%v (%v over thresholds)", m.table.Title, offending)
+	}
+	m.table.RowColors = rowColors(mergeCellStates(rowStates, cellStates))
+	m.table.CellColors = make([][]termui.Color, len(cellStates))
+	for i, states := range cellStates {
+		m.table.CellColors[i] = rowColors(states)
+	}
+	m.table.SortColumn = resources.GetSortColumn()
+	m.table.SortDescending = m.sortOrder == resource.Descending
+	// show the sort key on the title if it has no column
+	if m.table.SortColumn < 0 && m.sortType != resource.ByName {
+		arrow := "▲"
+		if m.sortOrder == resource.Descending {
+			arrow = "▼"
+		}
+		m.table.Title = fmt.Sprintf("%v (sort: %v%v)", m.table.Title, m.sortType, arrow)
+	}
+}
+
+// SetThresholds sets the percentages of the usages above which the rows are highlighted.
+func (m *Monitor) SetThresholds(thresholds resource.Thresholds) {
+	m.thresholds = thresholds
+}
+
+func countOffendingRows(cellStates [][]resource.RowState) int {
+	count := 0
+	for _, states := range cellStates {
+		if worstState(states) != resource.RowNormal {
+			count++
+		}
+	}
+	return count
+}
+
+// mergeCellStates raises the states of the rows to the worst state of their cells.
+func mergeCellStates(rowStates []resource.RowState, cellStates [][]resource.RowState) []resource.RowState {
+	merged := make([]resource.RowState, IntMax(len(rowStates), len(cellStates)))
+	copy(merged, rowStates)
+	for i, states := range cellStates {
+		if state := worstState(states); state > merged[i] {
+			merged[i] = state
+		}
+	}
+	return merged
 }
 
-func (m *Monitor) updateAllGraph(nodeList *corev1.NodeList, all *resource.Resource) error {
-	cpuUsage, cpuUsageStr := all.GetCpuUsage()
-	memUsage, memUsageStr := all.GetMemoryUsage()
+func worstState(states []resource.RowState) resource.RowState {
+	worst := resource.RowNormal
+	for _, state := range states {
+		if state > worst {
+			worst = state
+		}
+	}
+	return worst
+}
+
+func rowColors(states []resource.RowState) []termui.Color {
+	colors := make([]termui.Color, len(states))
+	for i, state := range states {
+		switch state {
+		case resource.RowWarning:
+			colors[i] = warningRowColor
+		case resource.RowError:
+			colors[i] = errorRowColor
+		default:
+			colors[i] = termui.ColorClear
+		}
+	}
+	return colors
+}
 
-	limitCpuLabel := containerLimitLabel
-	limitCpu, limitCpuStr, cok := all.GetCpuLimits()
-	limitMemoryLabel := containerLimitLabel
-	limitMemory, limitMemoryStr, mok := all.GetMemoryLimits()
+func (m *Monitor) updateSummarizedGraph(nodeList *corev1.NodeList, summarized *resource.SummarizedResource) {
+	series := m.podHistory.get(podKey(summarized.GetNamespace(), summarized.GetPodName()))
+	_, cpuUsageStr := summarized.GetCpuUsage()
+	_, memUsageStr := summarized.GetMemoryUsage()
+	allocatable := nodeAllocatable(summarized.GetNodeName(), nodeList)
+
+	m.followLogs(summarized.GetNamespace(), summarized.GetPodName())
+
+	header := fmt.Sprintf("Name: %v", summarized.GetPodName())
+	cpuLimit, cpuLimitStr, cok := summarized.GetCpuLimits()
+	cpuRequest, cpuRequestStr, cpuRequestOk := summarized.GetCpuRequests()
+	setGraph(m.cpuGraph, header, series.CPU(), fmt.Sprintf("Usage: %v", cpuUsageStr), cpuTickFormatter,
+		graphLines{}.
+			add(podRequestLabel, graphRequestColor, cpuRequest, cpuRequestStr, cpuRequestOk).
+			add(podLimitLabel, graphLimitColor, cpuLimit, cpuLimitStr, cok).
+			add(nodeAllocatableLabel, graphAllocatableColor,
+				GetResourceValue(allocatable, corev1.ResourceCPU),
+				GetResourceValueString(allocatable, corev1.ResourceCPU),
+				!cok && len(allocatable) > 0),
+	)
+
+	memLimit, memLimitStr, mok := summarized.GetMemoryLimits()
+	memRequest, memRequestStr, memRequestOk := summarized.GetMemoryRequests()
+	setGraph(m.memGraph, header, series.Memory(), fmt.Sprintf("Usage: %v", memUsageStr), memoryTickFormatter,
+		graphLines{}.
+			add(podRequestLabel, graphRequestColor, memRequest, memRequestStr, memRequestOk).
+			add(podLimitLabel, graphLimitColor, memLimit, memLimitStr, mok).
+			add(nodeAllocatableLabel, graphAllocatableColor,
+				GetResourceValue(allocatable, corev1.ResourceMemory),
+				GetResourceValueString(allocatable, corev1.ResourceMemory),
+				!mok && len(allocatable) > 0),
+	)
+}
 
-	var node *corev1.Node
-	if !cok || !mok {
-		node = FindNode(all.GetNodeName(), nodeList.Items)
+// followLogs switches the logs pane to the given pod
+// and stops following the previous one.
+func (m *Monitor) followLogs(namespace, podName string) {
+	key := podKey(namespace, podName)
+	if m.logStream == nil || m.logStreamKey != key {
+		m.stopLogs()
+		m.logStream = followLogs(func() (io.ReadCloser, error) {
+			return m.source.FollowPodLogs(namespace, podName)
+		})
+		m.logStreamKey = key
 	}
-	if !cok {
-		limitCpuLabel = nodeAllocatableLabel
-		limitCpu = GetResourceValue(node.Status.Allocatable, corev1.ResourceCPU)
-		limitCpuStr = GetResourceValueString(node.Status.Allocatable, corev1.ResourceCPU)
+	m.logs.Text = m.logStream.text()
+}
+
+func (m *Monitor) stopLogs() {
+	if m.logStream != nil {
+		m.logStream.close()
+		m.logStream = nil
 	}
-	if !mok {
-		limitMemoryLabel = nodeAllocatableLabel
-		limitMemory = GetResourceValue(node.Status.Allocatable, corev1.ResourceMemory)
-		limitMemoryStr = GetResourceValueString(node.Status.Allocatable, corev1.ResourceMemory)
+}
+
+// Close stops following the logs and watching the cluster.
+func (m *Monitor) Close() {
+	m.stopLogs()
+	if m.alertEngine != nil {
+		m.alertEngine.Close()
 	}
+	m.source.Close()
+}
 
-	m.cpuGraph.LabelHeader = fmt.Sprintf("Name: %v", all.GetContainerName())
-	m.cpuGraph.Data = append(m.cpuGraph.Data, cpuUsage)
-	m.cpuGraph.LabelData = fmt.Sprintf("Usage: %v", cpuUsageStr)
-	m.cpuGraph.UpperLimit = limitCpu
-	m.cpuGraph.DrawUpperLimit = false
-	m.cpuGraph.LabelUpperLimit = fmt.Sprintf("%v: %v", limitCpuLabel, limitCpuStr)
+func (m *Monitor) updateAllGraph(nodeList *corev1.NodeList, all *resource.Resource) {
+	series := m.containerHistory.get(containerKey(all.GetNamespace(), all.GetPodName(), all.GetContainerName()))
+	_, cpuUsageStr := all.GetCpuUsage()
+	_, memUsageStr := all.GetMemoryUsage()
+	// the node allocatable is drawn instead of undefined limits
+	allocatable := nodeAllocatable(all.GetNodeName(), nodeList)
+
+	header := fmt.Sprintf("Name: %v", all.GetContainerName())
+	cpuLimit, cpuLimitStr, cok := all.GetCpuLimits()
+	cpuRequest, cpuRequestStr, cpuRequestOk := all.GetCpuRequests()
+	setGraph(m.cpuGraph, header, series.CPU(), fmt.Sprintf("Usage: %v", cpuUsageStr), cpuTickFormatter,
+		graphLines{}.
+			add(containerRequestLabel, graphRequestColor, cpuRequest, cpuRequestStr, cpuRequestOk).
+			add(containerLimitLabel, graphLimitColor, cpuLimit, cpuLimitStr, cok).
+			add(nodeAllocatableLabel, graphAllocatableColor,
+				GetResourceValue(allocatable, corev1.ResourceCPU),
+				GetResourceValueString(allocatable, corev1.ResourceCPU),
+				!cok && len(allocatable) > 0),
+	)
+
+	memLimit, memLimitStr, mok := all.GetMemoryLimits()
+	memRequest, memRequestStr, memRequestOk := all.GetMemoryRequests()
+	setGraph(m.memGraph, header, series.Memory(), fmt.Sprintf("Usage: %v", memUsageStr), memoryTickFormatter,
+		graphLines{}.
+			add(containerRequestLabel, graphRequestColor, memRequest, memRequestStr, memRequestOk).
+			add(containerLimitLabel, graphLimitColor, memLimit, memLimitStr, mok).
+			add(nodeAllocatableLabel, graphAllocatableColor,
+				GetResourceValue(allocatable, corev1.ResourceMemory),
+				GetResourceValueString(allocatable, corev1.ResourceMemory),
+				!mok && len(allocatable) > 0),
+	)
+}
 
-	m.memGraph.LabelHeader = fmt.Sprintf("Name: %v", all.GetContainerName())
-	m.memGraph.Data = append(m.memGraph.Data, memUsage)
-	m.memGraph.LabelData = fmt.Sprintf("Usage: %v", memUsageStr)
-	m.memGraph.UpperLimit = limitMemory
-	m.memGraph.DrawUpperLimit = false
-	m.memGraph.LabelUpperLimit = fmt.Sprintf("%v: %v", limitMemoryLabel, limitMemoryStr)
-	return nil
+// nodeAllocatable returns the allocatable of the node,
+// which is empty for the pods not scheduled yet.
+func nodeAllocatable(nodeName string, nodeList *corev1.NodeList) corev1.ResourceList {
+	node := FindNode(nodeName, nodeList.Items)
+	if node == nil {
+		return corev1.ResourceList{}
+	}
+	return node.Status.Allocatable
 }
 
-func (m *Monitor) updateNodeGraph(node *resource.NodeResource) error {
-	cpuUsage, cpuUsageStr := node.GetCpuUsagePercentage()
-	memUsage, memUsageStr := node.GetMemoryUsagePercentage()
+func (m *Monitor) updateNodeGraph(node *resource.NodeResource) {
+	series := m.nodeHistory.get(nodeKey(node.GetNodeName()))
+	_, cpuUsageStr := node.GetCpuUsagePercentage()
+	_, memUsageStr := node.GetMemoryUsagePercentage()
+
+	header := fmt.Sprintf("Name: %v", node.GetNodeName())
+	setGraph(m.cpuGraph, header, series.CPU(), fmt.Sprintf("%%Usage: %v", cpuUsageStr), percentageTickFormatter,
+		allocatedLines(node.GetCpuRequestsPercentage, node.GetCpuLimitsPercentage),
+	)
+	setGraph(m.memGraph, header, series.Memory(), fmt.Sprintf("%%Usage: %v", memUsageStr), percentageTickFormatter,
+		allocatedLines(node.GetMemoryRequestsPercentage, node.GetMemoryLimitsPercentage),
+	)
+}
 
-	m.cpuGraph.LabelHeader = fmt.Sprintf("Name: %v", node.GetNodeName())
-	m.cpuGraph.Data = append(m.cpuGraph.Data, cpuUsage)
-	m.cpuGraph.UpperLimit = 100.
-	m.cpuGraph.DrawUpperLimit = false
-	m.cpuGraph.LabelData = fmt.Sprintf("%%Usage: %v", cpuUsageStr)
+// allocatedLines draws the sums of requests/limits of the pods against the allocatable of the node.
+func allocatedLines(requests, limits func() (float64, string)) graphLines {
+	requestsVal, requestsStr := requests()
+	limitsVal, limitsStr := limits()
+	return graphLines{}.
+		add("%Requests", graphRequestColor, requestsVal, requestsStr, requestsStr != "-").
+		add("%Limits", graphLimitColor, limitsVal, limitsStr, limitsStr != "-").
+		add(nodeAllocatableLabel, graphAllocatableColor, 100, "100%", true)
+}
 
-	m.memGraph.LabelHeader = fmt.Sprintf("Name: %v", node.GetNodeName())
-	m.memGraph.Data = append(m.memGraph.Data, memUsage)
-	m.memGraph.UpperLimit = 100.
-	m.memGraph.DrawUpperLimit = false
-	m.memGraph.LabelData = fmt.Sprintf("%%Usage: %v", memUsageStr)
-	return nil
+func (m *Monitor) updateNodeDetail(node *resource.NodeResource) {
+	m.logs.Title = nodeDetailTitle
+	m.logs.Text = node.Detail()
 }
@@ -0,0 +1,85 @@
+package ktop
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	// number of lines kept for the logs pane
+	maxLogLines = 30
+)
+
+// logStream follows the logs of a pod in background.
+type logStream struct {
+	mu     sync.Mutex
+	lines  []string
+	err    error
+	closed bool
+	stream io.ReadCloser
+}
+
+func followLogs(open func() (io.ReadCloser, error)) *logStream {
+	s := &logStream{}
+	go s.run(open)
+	return s
+}
+
+func (s *logStream) run(open func() (io.ReadCloser, error)) {
+	stream, err := open()
+	s.mu.Lock()
+	if err != nil {
+		s.err = err
+		s.mu.Unlock()
+		return
+	}
+	if s.closed {
+		s.mu.Unlock()
+		stream.Close()
+		return
+	}
+	s.stream = stream
+	s.mu.Unlock()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		s.mu.Lock()
+		s.lines = append(s.lines, scanner.Text())
+		if len(s.lines) > maxLogLines {
+			s.lines = s.lines[len(s.lines)-maxLogLines:]
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := scanner.Err(); err != nil && !s.closed {
+		s.err = err
+	}
+}
+
+func (s *logStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.stream != nil {
+		s.stream.Close()
+	}
+}
+
+func (s *logStream) text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err.Error()
+	}
+	if len(s.lines) == 0 {
+		return "Loading..."
+	}
+	return strings.Join(s.lines, "\n")
+}
@@ -0,0 +1,67 @@
+package ktop
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+const (
+	namespaceRequestsQuotaLabel = "RequestsQuota"
+	namespaceLimitsQuotaLabel   = "LimitsQuota"
+)
+
+// aggregateNamespaces sums up the pods for each namespace
+// with the quotas of the namespace.
+func (m *Monitor) aggregateNamespaces(pods []*resource.SummarizedResource, quotaList []corev1.ResourceQuota) []*resource.NamespaceResource {
+	quotas := make(map[string][]corev1.ResourceQuota)
+	for _, quota := range quotaList {
+		quotas[quota.Namespace] = append(quotas[quota.Namespace], quota)
+	}
+
+	namespaces := make([]*resource.NamespaceResource, 0)
+	index := make(map[string]*resource.NamespaceResource)
+	get := func(name string) *resource.NamespaceResource {
+		namespace, ok := index[name]
+		if !ok {
+			namespace = resource.NewNamespaceResource(name, quotas[name])
+			index[name] = namespace
+			namespaces = append(namespaces, namespace)
+		}
+		return namespace
+	}
+	for _, pod := range pods {
+		get(pod.GetNamespace()).Add(pod)
+	}
+	// show the namespaces which have quotas but no pods
+	for name := range quotas {
+		get(name)
+	}
+	return namespaces
+}
+
+func (m *Monitor) updateNamespaceGraph(namespace *resource.NamespaceResource) {
+	series := m.namespaceHistory.get(namespace.GetNamespace())
+	_, cpuUsageStr := namespace.GetCpuUsage()
+	_, memUsageStr := namespace.GetMemoryUsage()
+
+	header := fmt.Sprintf("Name: %v (%v pods)", namespace.GetNamespace(), namespace.GetPods())
+
+	cpuLimit, cpuLimitStr, cok := namespace.GetCpuLimitsQuota()
+	cpuRequest, cpuRequestStr, cpuRequestOk := namespace.GetCpuRequestsQuota()
+	setGraph(m.cpuGraph, header, series.CPU(), fmt.Sprintf("Usage: %v", cpuUsageStr), cpuTickFormatter,
+		graphLines{}.
+			add(namespaceRequestsQuotaLabel, graphRequestColor, cpuRequest, cpuRequestStr, cpuRequestOk).
+			add(namespaceLimitsQuotaLabel, graphLimitColor, cpuLimit, cpuLimitStr, cok),
+	)
+
+	memLimit, memLimitStr, mok := namespace.GetMemoryLimitsQuota()
+	memRequest, memRequestStr, memRequestOk := namespace.GetMemoryRequestsQuota()
+	setGraph(m.memGraph, header, series.Memory(), fmt.Sprintf("Usage: %v", memUsageStr), memoryTickFormatter,
+		graphLines{}.
+			add(namespaceRequestsQuotaLabel, graphRequestColor, memRequest, memRequestStr, memRequestOk).
+			add(namespaceLimitsQuotaLabel, graphLimitColor, memLimit, memLimitStr, mok),
+	)
+}
@@ -0,0 +1,103 @@
+package ktop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+// view is a table drilled down from a row of the parent table.
+type view struct {
+	tableType string
+	// scope of the rows, empty for all
+	nodeName  string
+	namespace string
+	podName   string
+	// shown on the breadcrumb
+	label string
+	// state of the parent table which is restored on going back
+	parentRow       int
+	parentSortType  resource.SortType
+	parentSortOrder resource.SortOrder
+}
+
+func (v *view) contains(namespace, podName, nodeName string) bool {
+	return (v.nodeName == "" || v.nodeName == nodeName) &&
+		(v.namespace == "" || v.namespace == namespace) &&
+		(v.podName == "" || v.podName == podName)
+}
+
+// tableType returns the type of the table shown now.
+func (m *Monitor) tableType() string {
+	if n := len(m.views); n > 0 {
+		return m.views[n-1].tableType
+	}
+	return m.tableTypeCircle.Value.(string)
+}
+
+// inScope tells whether the pod is in the scope of the drilled down views.
+func (m *Monitor) inScope(namespace, podName, nodeName string) bool {
+	for _, v := range m.views {
+		if !v.contains(namespace, podName, nodeName) {
+			return false
+		}
+	}
+	return true
+}
+
+// DrillDown opens the pods on the selected node, or the containers of the selected pod.
+func (m *Monitor) DrillDown() {
+	var next *view
+	switch selected := m.selected.(type) {
+	case *resource.NodeResource:
+		next = &view{
+			tableType: resource.SummarizedType,
+			nodeName:  selected.GetNodeName(),
+			label:     fmt.Sprintf("Node: %v", selected.GetNodeName()),
+		}
+	case *resource.SummarizedResource:
+		next = &view{
+			tableType: resource.AllType,
+			namespace: selected.GetNamespace(),
+			podName:   selected.GetPodName(),
+			label:     fmt.Sprintf("Pod: %v/%v", selected.GetNamespace(), selected.GetPodName()),
+		}
+	default:
+		return
+	}
+	next.parentRow = m.table.SelectedRow
+	next.parentSortType = m.sortType
+	next.parentSortOrder = m.sortOrder
+	m.views = append(m.views, next)
+	m.keepSortType()
+	m.resetGraph()
+	m.resetTable()
+	m.resetLogs()
+	m.refresh()
+}
+
+// GoBack returns to the parent table of the drilled down one.
+func (m *Monitor) GoBack() {
+	n := len(m.views)
+	if n == 0 {
+		return
+	}
+	last := m.views[n-1]
+	m.views = m.views[:n-1]
+	m.sortType = last.parentSortType
+	m.sortOrder = last.parentSortOrder
+	m.resetGraph()
+	m.resetTable()
+	m.resetLogs()
+	m.table.SelectedRow = last.parentRow
+	m.refresh()
+}
+
+func (m *Monitor) breadcrumb() string {
+	labels := make([]string, len(m.views))
+	for i, v := range m.views {
+		labels[i] = v.label
+	}
+	return strings.Join(labels, " › ")
+}
@@ -0,0 +1,158 @@
+package ktop
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gizak/termui/v3"
+
+	"github.com/ynqa/ktop/pkg/resource"
+	"github.com/ynqa/ktop/pkg/ui"
+)
+
+var (
+	// colors of the series of the marked rows
+	markColors = []termui.Color{
+		termui.ColorGreen,
+		termui.ColorCyan,
+		termui.ColorMagenta,
+		termui.ColorYellow,
+		termui.Color(208),
+		termui.ColorBlue,
+		termui.Color(141),
+		termui.ColorWhite,
+	}
+)
+
+// mark is a row of the table whose usages are overlaid on the graphs.
+type mark struct {
+	key   string
+	color termui.Color
+}
+
+// historyKey returns the key of the resource in the history.
+func historyKey(r interface{}) (string, bool) {
+	switch r := r.(type) {
+	case *resource.SummarizedResource:
+		return podKey(r.GetNamespace(), r.GetPodName()), true
+	case *resource.Resource:
+		return containerKey(r.GetNamespace(), r.GetPodName(), r.GetContainerName()), true
+	case *resource.NodeResource:
+		return nodeKey(r.GetNodeName()), true
+	case *resource.WorkloadResource:
+		return workloadKey(r.GetNamespace(), r.GetKind(), r.GetWorkloadName()), true
+	case *resource.NamespaceResource:
+		return r.GetNamespace(), true
+	default:
+		return "", false
+	}
+}
+
+func (m *Monitor) historyOf(tableType string) *history {
+	switch tableType {
+	case resource.AllType:
+		return m.containerHistory
+	case resource.NodeType:
+		return m.nodeHistory
+	case resource.WorkloadType:
+		return m.workloadHistory
+	case resource.NamespaceType:
+		return m.namespaceHistory
+	default:
+		return m.podHistory
+	}
+}
+
+// ToggleMark marks or unmarks the selected row to compare it with the other marked rows on the graphs.
+func (m *Monitor) ToggleMark() {
+	key, ok := historyKey(m.selected)
+	if !ok {
+		return
+	}
+	for i, mk := range m.marks {
+		if mk.key == key {
+			m.marks = append(m.marks[:i], m.marks[i+1:]...)
+			m.resetGraph()
+			m.refresh()
+			return
+		}
+	}
+	m.marks = append(m.marks, &mark{key: key, color: m.nextMarkColor()})
+	m.resetGraph()
+	m.refresh()
+}
+
+// nextMarkColor returns the first color not in use to keep the colors of the marked rows.
+func (m *Monitor) nextMarkColor() termui.Color {
+	used := make(map[termui.Color]int)
+	for _, mk := range m.marks {
+		used[mk.color]++
+	}
+	for n := 0; ; n++ {
+		for _, color := range markColors {
+			if used[color] == n {
+				return color
+			}
+		}
+	}
+}
+
+func (m *Monitor) markColor(key string) termui.Color {
+	for _, mk := range m.marks {
+		if mk.key == key {
+			return mk.color
+		}
+	}
+	return termui.ColorClear
+}
+
+// updateMarks shows markers on the marked rows in the colors of their series.
+func (m *Monitor) updateMarks(rows int, row func(int) interface{}) {
+	colors := make([]termui.Color, rows)
+	for i := range colors {
+		colors[i] = termui.ColorClear
+		if key, ok := historyKey(row(i)); ok {
+			colors[i] = m.markColor(key)
+		}
+	}
+	m.table.MarkColors = colors
+}
+
+// updateOverlayGraph overlays the usages of the marked rows on the graphs instead of the selected row.
+func (m *Monitor) updateOverlayGraph() {
+	cpuFormatter, memFormatter := cpuTickFormatter, memoryTickFormatter
+	if m.tableType() == resource.NodeType {
+		cpuFormatter, memFormatter = percentageTickFormatter, percentageTickFormatter
+	}
+	h := m.historyOf(m.tableType())
+	cpu, mem := make([]ui.Series, 0), make([]ui.Series, 0)
+	for _, mk := range m.marks {
+		// the resource may be deleted
+		series := h.get(mk.key)
+		if series == nil {
+			continue
+		}
+		cpu = append(cpu, overlaySeries(mk, series.CPU(), cpuFormatter))
+		mem = append(mem, overlaySeries(mk, series.Memory(), memFormatter))
+	}
+
+	header := fmt.Sprintf("Comparing %v rows", len(cpu))
+	for _, g := range []*ui.Graph{m.cpuGraph, m.memGraph} {
+		g.LabelHeader = header
+		g.ReferenceLines = nil
+	}
+	m.cpuGraph.Series, m.cpuGraph.YTickFormatter = cpu, cpuFormatter
+	m.memGraph.Series, m.memGraph.YTickFormatter = mem, memFormatter
+}
+
+func overlaySeries(mk *mark, data []float64, formatter func(float64) string) ui.Series {
+	label := mk.key
+	if len(data) > 0 && !math.IsNaN(data[len(data)-1]) {
+		label = fmt.Sprintf("%v: %v", mk.key, formatter(data[len(data)-1]))
+	}
+	return ui.Series{
+		Label: label,
+		Data:  data,
+		Color: mk.color,
+	}
+}
@@ -0,0 +1,293 @@
+package ktop
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A session file is a sequence of gzip members, each of which is a snapshot in JSON.
+// The time of the snapshot is also put in the comment of the gzip header,
+// so that the player can index the file without decoding the snapshots.
+// Appending a member keeps the file valid, and an incomplete member at the end,
+// e.g. when the recording is killed, is ignored.
+
+// SessionWriter appends snapshots to a session file.
+type SessionWriter struct {
+	file *os.File
+	n    int
+}
+
+// NewSessionWriter creates the session file, which must not exist,
+// since the player searches the snapshots in the order of their times.
+func NewSessionWriter(path string) (*SessionWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return nil, errors.Errorf("%v already exists, choose another file to record", path)
+	} else if err != nil {
+		return nil, err
+	}
+	return &SessionWriter{file: f}, nil
+}
+
+func (w *SessionWriter) Write(snapshot *Snapshot) error {
+	var buf bytes.Buffer
+	z, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	z.Comment = snapshot.Time.Format(time.RFC3339Nano)
+	if err := json.NewEncoder(z).Encode(compactSnapshot(snapshot)); err != nil {
+		return err
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	// write a member at once not to leave a partial one on failures
+	if _, err := w.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// compactSnapshot returns a copy of the snapshot with only the fields which build the resources,
+// not to record e.g. the annotations, the volumes and the images of the nodes at every update.
+func compactSnapshot(s *Snapshot) *Snapshot {
+	c := *s
+	// the pods on the node whose detail is opened are not replayed
+	c.DetailNode, c.NodePods, c.NodePodsError = "", nil, ""
+
+	c.Nodes = make([]corev1.Node, len(s.Nodes))
+	for i, n := range s.Nodes {
+		c.Nodes[i] = corev1.Node{
+			ObjectMeta: compactObjectMeta(n.ObjectMeta),
+			Spec: corev1.NodeSpec{
+				Unschedulable: n.Spec.Unschedulable,
+				Taints:        n.Spec.Taints,
+			},
+			Status: corev1.NodeStatus{
+				Capacity:    n.Status.Capacity,
+				Allocatable: n.Status.Allocatable,
+				Conditions:  n.Status.Conditions,
+				Addresses:   n.Status.Addresses,
+				NodeInfo: corev1.NodeSystemInfo{
+					KubeletVersion: n.Status.NodeInfo.KubeletVersion,
+				},
+			},
+		}
+	}
+	c.Pods = make([]corev1.Pod, len(s.Pods))
+	for i, p := range s.Pods {
+		c.Pods[i] = corev1.Pod{
+			ObjectMeta: compactObjectMeta(p.ObjectMeta),
+			Spec: corev1.PodSpec{
+				NodeName:       p.Spec.NodeName,
+				InitContainers: compactContainers(p.Spec.InitContainers),
+				Containers:     compactContainers(p.Spec.Containers),
+			},
+			Status: corev1.PodStatus{
+				Phase:                 p.Status.Phase,
+				Conditions:            p.Status.Conditions,
+				Reason:                p.Status.Reason,
+				StartTime:             p.Status.StartTime,
+				InitContainerStatuses: p.Status.InitContainerStatuses,
+				ContainerStatuses:     p.Status.ContainerStatuses,
+			},
+		}
+	}
+	c.Quotas = make([]corev1.ResourceQuota, len(s.Quotas))
+	for i, q := range s.Quotas {
+		c.Quotas[i] = corev1.ResourceQuota{
+			ObjectMeta: compactObjectMeta(q.ObjectMeta),
+			Spec:       q.Spec,
+			Status:     q.Status,
+		}
+	}
+	return &c
+}
+
+func compactObjectMeta(m metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              m.Name,
+		Namespace:         m.Namespace,
+		Labels:            m.Labels,
+		CreationTimestamp: m.CreationTimestamp,
+		DeletionTimestamp: m.DeletionTimestamp,
+	}
+}
+
+func compactContainers(containers []corev1.Container) []corev1.Container {
+	compacted := make([]corev1.Container, len(containers))
+	for i, c := range containers {
+		compacted[i] = corev1.Container{
+			Name:      c.Name,
+			Resources: c.Resources,
+		}
+	}
+	return compacted
+}
+
+// Len returns the number of snapshots written by the writer.
+func (w *SessionWriter) Len() int {
+	return w.n
+}
+
+func (w *SessionWriter) Close() error {
+	return w.file.Close()
+}
+
+// countingReader counts the bytes read, which are the offsets of the gzip members.
+// It implements io.ByteReader for gzip not to read ahead of the member.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// Player replays the snapshots of a session file as the source of the monitor.
+type Player struct {
+	file    *os.File
+	offsets []int64
+	times   []time.Time
+	pos     int
+}
+
+// OpenPlayer indexes the snapshots in the session file.
+func OpenPlayer(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Player{file: f}
+	if err := p.index(); err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "failed to read %v", path)
+	}
+	if len(p.offsets) == 0 {
+		f.Close()
+		return nil, errors.Errorf("no snapshots in %v", path)
+	}
+	return p, nil
+}
+
+func (p *Player) index() error {
+	cr := &countingReader{r: bufio.NewReader(p.file)}
+	z := new(gzip.Reader)
+	for {
+		offset := cr.n
+		if err := z.Reset(cr); err == io.EOF {
+			return nil
+		} else if err == io.ErrUnexpectedEOF {
+			// the member is incomplete
+			return nil
+		} else if err != nil {
+			return err
+		}
+		z.Multistream(false)
+		t, err := time.Parse(time.RFC3339Nano, z.Comment)
+		if err != nil {
+			return errors.Wrapf(err, "invalid snapshot at %v", offset)
+		}
+		if _, err := io.Copy(ioutil.Discard, z); err == io.ErrUnexpectedEOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		p.offsets = append(p.offsets, offset)
+		p.times = append(p.times, t)
+	}
+}
+
+// Len returns the number of snapshots.
+func (p *Player) Len() int {
+	return len(p.offsets)
+}
+
+// Position returns the index of the snapshot to replay.
+func (p *Player) Position() int {
+	return p.pos
+}
+
+// Time returns the time when the i-th snapshot was recorded.
+func (p *Player) Time(i int) time.Time {
+	return p.times[i]
+}
+
+// Seek moves to the i-th snapshot, which is clamped into the session.
+func (p *Player) Seek(i int) {
+	if i < 0 {
+		i = 0
+	} else if i >= len(p.offsets) {
+		i = len(p.offsets) - 1
+	}
+	p.pos = i
+}
+
+// SeekTime moves to the first snapshot recorded at or after the time.
+func (p *Player) SeekTime(t time.Time) {
+	p.Seek(sort.Search(len(p.times), func(i int) bool {
+		return !p.times[i].Before(t)
+	}))
+}
+
+// Interval returns the average interval between the snapshots.
+func (p *Player) Interval() time.Duration {
+	if len(p.times) < 2 {
+		return 0
+	}
+	return p.times[len(p.times)-1].Sub(p.times[0]) / time.Duration(len(p.times)-1)
+}
+
+// Snapshot decodes the snapshot at the position.
+func (p *Player) Snapshot() (*Snapshot, error) {
+	if _, err := p.file.Seek(p.offsets[p.pos], io.SeekStart); err != nil {
+		return nil, err
+	}
+	z, err := gzip.NewReader(bufio.NewReader(p.file))
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	z.Multistream(false)
+	snapshot := new(Snapshot)
+	if err := json.NewDecoder(z).Decode(snapshot); err != nil {
+		return nil, errors.Wrapf(err, "invalid snapshot at %v", p.offsets[p.pos])
+	}
+	return snapshot, nil
+}
+
+func (p *Player) FollowPodLogs(namespace, podName string) (io.ReadCloser, error) {
+	return nil, errors.New("logs are not recorded in the session")
+}
+
+// WatchNodePods does nothing since the pods on the nodes are not recorded.
+func (p *Player) WatchNodePods(nodeName string) {}
+
+func (p *Player) Close() {
+	p.file.Close()
+}
@@ -0,0 +1,203 @@
+package ktop
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var sessionStart = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testSnapshot(i int) *Snapshot {
+	return &Snapshot{
+		Time: sessionStart.Add(time.Duration(i) * 5 * time.Second),
+		Nodes: []corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: kr.MustParse("2")},
+				Images:      []corev1.ContainerImage{{Names: []string{"nginx"}}},
+			},
+		}},
+		Pods: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "web",
+				Labels:      map[string]string{"app": "web"},
+				Annotations: map[string]string{"note": "not recorded"},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Volumes:  []corev1.Volume{{Name: "data"}},
+				Containers: []corev1.Container{{
+					Name:  "app",
+					Image: "nginx",
+					Env:   []corev1.EnvVar{{Name: "SECRET", Value: "not recorded"}},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceCPU: kr.MustParse("500m")},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}},
+		DetailNode: "node-1",
+		NodePods:   &corev1.PodList{},
+	}
+}
+
+// writeTestSession records the snapshots to a new session file.
+func writeTestSession(t *testing.T, n int) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "ktop")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "session.ktop")
+	w, err := NewSessionWriter(path)
+	if err != nil {
+		t.Fatalf("NewSessionWriter() error = %v", err)
+	}
+	for i := 0; i < n; i++ {
+		if err := w.Write(testSnapshot(i)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if w.Len() != n {
+		t.Errorf("Len() of the writer = %v, want %v", w.Len(), n)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return path
+}
+
+func openTestPlayer(t *testing.T, path string) *Player {
+	t.Helper()
+	p, err := OpenPlayer(path)
+	if err != nil {
+		t.Fatalf("OpenPlayer() error = %v", err)
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestSession(t *testing.T) {
+	path := writeTestSession(t, 3)
+	p := openTestPlayer(t, path)
+
+	if p.Len() != 3 {
+		t.Fatalf("Len() = %v, want 3", p.Len())
+	}
+	for i := 0; i < p.Len(); i++ {
+		if want := testSnapshot(i).Time; !p.Time(i).Equal(want) {
+			t.Errorf("Time(%v) = %v, want %v", i, p.Time(i), want)
+		}
+	}
+	if got := p.Interval(); got != 5*time.Second {
+		t.Errorf("Interval() = %v, want 5s", got)
+	}
+
+	seeks := []struct {
+		name string
+		seek func()
+		want int
+	}{
+		{name: "before the start", seek: func() { p.Seek(-1) }, want: 0},
+		{name: "after the end", seek: func() { p.Seek(3) }, want: 2},
+		{name: "in the session", seek: func() { p.Seek(1) }, want: 1},
+		{name: "time between snapshots", seek: func() { p.SeekTime(sessionStart.Add(7 * time.Second)) }, want: 2},
+		{name: "time of a snapshot", seek: func() { p.SeekTime(sessionStart.Add(5 * time.Second)) }, want: 1},
+		{name: "time before the start", seek: func() { p.SeekTime(sessionStart.Add(-time.Minute)) }, want: 0},
+	}
+	for _, tt := range seeks {
+		tt.seek()
+		if p.Position() != tt.want {
+			t.Errorf("%v: Position() = %v, want %v", tt.name, p.Position(), tt.want)
+		}
+	}
+
+	p.Seek(1)
+	snapshot, err := p.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if !snapshot.Time.Equal(p.Time(1)) {
+		t.Errorf("time of the snapshot = %v, want %v", snapshot.Time, p.Time(1))
+	}
+	if len(snapshot.Pods) != 1 || len(snapshot.Nodes) != 1 {
+		t.Fatalf("snapshot = %+v, want a pod and a node", snapshot)
+	}
+
+	// only the fields which build the resources are recorded
+	pod, node := snapshot.Pods[0], snapshot.Nodes[0]
+	if pod.Name != "web" || pod.Labels["app"] != "web" || pod.Spec.NodeName != "node-1" || pod.Status.Phase != corev1.PodRunning {
+		t.Errorf("pod = %+v, want default/web running on node-1", pod)
+	}
+	if limit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]; limit.MilliValue() != 500 {
+		t.Errorf("cpu limit of the container = %v, want 500m", limit.String())
+	}
+	if allocatable := node.Status.Allocatable[corev1.ResourceCPU]; allocatable.Value() != 2 {
+		t.Errorf("cpu allocatable of the node = %v, want 2", allocatable.String())
+	}
+	if pod.Annotations != nil || pod.Spec.Volumes != nil || pod.Spec.Containers[0].Image != "" || pod.Spec.Containers[0].Env != nil {
+		t.Errorf("pod = %+v, want without annotations, volumes, images and environments", pod)
+	}
+	if node.Status.Images != nil {
+		t.Errorf("images of the node = %v, want nil", node.Status.Images)
+	}
+	if snapshot.DetailNode != "" || snapshot.NodePods != nil {
+		t.Errorf("pods on the node %q = %v, want not recorded", snapshot.DetailNode, snapshot.NodePods)
+	}
+}
+
+func TestSessionIncompleteMember(t *testing.T) {
+	path := writeTestSession(t, 3)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		// e.g. the recording is killed while writing the last member
+		{name: "truncated body", data: data[:len(data)-10], want: 2},
+		{name: "truncated header", data: append(append([]byte{}, data...), data[:5]...), want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncated := filepath.Join(filepath.Dir(path), "truncated.ktop")
+			if err := ioutil.WriteFile(truncated, tt.data, 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			p := openTestPlayer(t, truncated)
+			if p.Len() != tt.want {
+				t.Fatalf("Len() = %v, want %v", p.Len(), tt.want)
+			}
+			p.Seek(p.Len() - 1)
+			if _, err := p.Snapshot(); err != nil {
+				t.Errorf("Snapshot() of the last complete member error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewSessionWriterExisting(t *testing.T) {
+	path := writeTestSession(t, 1)
+	if _, err := NewSessionWriter(path); err == nil {
+		t.Fatalf("NewSessionWriter() of the existing file succeeded, want an error")
+	}
+	// the recorded session is kept
+	if p := openTestPlayer(t, path); p.Len() != 1 {
+		t.Errorf("Len() = %v, want 1", p.Len())
+	}
+}
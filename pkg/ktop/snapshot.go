@@ -0,0 +1,196 @@
+package ktop
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
+	"k8s.io/metrics/pkg/apis/metrics"
+
+	"github.com/ynqa/ktop/pkg/kube"
+)
+
+// Snapshot is the objects fetched from the cluster at an update,
+// which are the inputs to build the resources.
+type Snapshot struct {
+	Time        time.Time             `json:"time"`
+	Nodes       []corev1.Node         `json:"nodes"`
+	NodeMetrics []metrics.NodeMetrics `json:"nodeMetrics"`
+	Pods        []corev1.Pod          `json:"pods"`
+	PodMetrics  []metrics.PodMetrics  `json:"podMetrics"`
+	// node whose detail is opened, with the pods of all namespaces scheduled to it,
+	// which are nil until listed, and why they are not listed, e.g. forbidden
+	DetailNode    string                 `json:"detailNode,omitempty"`
+	NodePods      *corev1.PodList        `json:"nodePods,omitempty"`
+	NodePodsError string                 `json:"nodePodsError,omitempty"`
+	Quotas        []corev1.ResourceQuota `json:"quotas"`
+	// why the quotas are not watched, e.g. forbidden
+	QuotasError string `json:"quotasError,omitempty"`
+	// top-level controllers of the pods
+	Workloads map[string]Workload `json:"workloads"`
+	// why the controllers are not watched, e.g. forbidden
+	WorkloadsError string `json:"workloadsError,omitempty"`
+	// summaries of the kubelets, which are fetched only if enabled
+	Summaries []statsapi.Summary `json:"summaries,omitempty"`
+}
+
+type Workload struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Source provides the snapshots to the monitor.
+type Source interface {
+	// Snapshot returns the latest snapshot, or nil if there is nothing to update.
+	Snapshot() (*Snapshot, error)
+	FollowPodLogs(namespace, podName string) (io.ReadCloser, error)
+	// WatchNodePods lists the pods on the node in the next snapshots,
+	// while the detail of the node is opened. An empty name stops it.
+	WatchNodePods(nodeName string)
+	Close()
+}
+
+const (
+	// number of kubelets whose summaries are fetched at the same time
+	summaryConcurrency = 8
+)
+
+type clusterSource struct {
+	*kube.KubeClients
+	withSummaries bool
+}
+
+// NewClusterSource fetches the snapshots from the cluster,
+// with the summaries of the kubelets if withSummaries is set.
+func NewClusterSource(kubeclients *kube.KubeClients, withSummaries bool) Source {
+	return &clusterSource{
+		KubeClients:   kubeclients,
+		withSummaries: withSummaries,
+	}
+}
+
+func (c *clusterSource) Snapshot() (*Snapshot, error) {
+	nodeList, err := c.GetNodeList(c.Selectors.Node)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	s := &Snapshot{
+		Time:  time.Now(),
+		Nodes: nodeList.Items,
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 2)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		podMetricsList, err := c.GetPodMetricsList(*c.Flags.Namespace, c.Selectors.Pod)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		podList, err := c.GetPodList(*c.Flags.Namespace, c.Selectors.Pod)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		s.PodMetrics = podMetricsList.Items
+		s.Pods = podList.Items
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		nodeMetricsList, err := c.GetNodeMetricsList(c.Selectors.Node)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		s.NodeMetrics = nodeMetricsList.Items
+	}()
+	wg.Wait()
+	close(errCh)
+
+	var mergedError error
+	for err := range errCh {
+		if mergedError == nil {
+			mergedError = errors.New(err.Error())
+		}
+		mergedError = errors.Wrap(mergedError, err.Error())
+	}
+	if mergedError != nil {
+		return nil, mergedError
+	}
+
+	if nodeName := c.WatchingNodePods(); nodeName != "" {
+		s.DetailNode = nodeName
+		// the node detail is degraded rather than failing the update
+		if nodePodList, err := c.GetNodePodList(nodeName); err != nil {
+			s.NodePodsError = err.Error()
+		} else {
+			s.NodePods = nodePodList
+		}
+	}
+
+	if err := c.QuotasError(); err != nil {
+		s.QuotasError = err.Error()
+	} else {
+		quotaList, err := c.GetResourceQuotaList(*c.Flags.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		s.Quotas = quotaList.Items
+	}
+
+	if err := c.WorkloadsError(); err != nil {
+		s.WorkloadsError = err.Error()
+	} else {
+		s.Workloads = make(map[string]Workload, len(s.Pods))
+		for _, pod := range s.Pods {
+			kind, name, err := c.GetPodWorkload(pod.Namespace, pod.Name)
+			if err != nil {
+				// the pod may be deleted after listing
+				continue
+			}
+			s.Workloads[podKey(pod.Namespace, pod.Name)] = Workload{Kind: kind, Name: name}
+		}
+	}
+
+	if c.withSummaries {
+		s.Summaries = c.fetchSummaries(s.Nodes)
+	}
+	return s, nil
+}
+
+// fetchSummaries reads the summaries of the nodes in parallel,
+// and skips the nodes which do not respond, e.g. NotReady.
+func (c *clusterSource) fetchSummaries(nodes []corev1.Node) []statsapi.Summary {
+	summaries := make([]*statsapi.Summary, len(nodes))
+	sem := make(chan struct{}, summaryConcurrency)
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, nodeName string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			summary, err := c.GetNodeSummary(nodeName)
+			if err != nil {
+				return
+			}
+			summaries[i] = summary
+		}(i, node.Name)
+	}
+	wg.Wait()
+
+	fetched := make([]statsapi.Summary, 0, len(nodes))
+	for _, summary := range summaries {
+		if summary != nil {
+			fetched = append(fetched, *summary)
+		}
+	}
+	return fetched
+}
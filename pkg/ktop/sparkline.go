@@ -0,0 +1,93 @@
+package ktop
+
+import (
+	"fmt"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+const (
+	// samples shown in the sparkline of each row
+	sparklineWidth = 20
+)
+
+// SparklineMode decides the usage drawn as the trend of each row.
+type SparklineMode int
+
+const (
+	SparklineOff SparklineMode = iota
+	SparklineCPU
+	SparklineMemory
+)
+
+func (s SparklineMode) String() string {
+	switch s {
+	case SparklineCPU:
+		return "cpu"
+	case SparklineMemory:
+		return "memory"
+	default:
+		return "off"
+	}
+}
+
+// Next returns the mode to switch to from the mode.
+func (s SparklineMode) Next() SparklineMode {
+	return (s + 1) % (SparklineMemory + 1)
+}
+
+func ParseSparklineMode(s string) (SparklineMode, error) {
+	for _, mode := range []SparklineMode{SparklineOff, SparklineCPU, SparklineMemory} {
+		if mode.String() == s {
+			return mode, nil
+		}
+	}
+	return SparklineOff, fmt.Errorf("unknown sparkline mode: %v", s)
+}
+
+func (m *Monitor) SetSparklineMode(mode SparklineMode) {
+	m.sparklineMode = mode
+}
+
+// CycleSparkline switches the usage drawn as the trend of each row.
+func (m *Monitor) CycleSparkline() {
+	m.sparklineMode = m.sparklineMode.Next()
+	m.refresh()
+}
+
+// resetSparklines shows the column of sparklines for the tables which have the history of each row.
+func (m *Monitor) resetSparklines() {
+	m.table.Sparklines = nil
+	m.table.SparklineWidth = 0
+	if m.sparklineMode == SparklineOff {
+		return
+	}
+	switch m.tableType() {
+	case resource.SummarizedType, resource.AllType, resource.NodeType:
+	default:
+		return
+	}
+	m.table.SparklineWidth = sparklineWidth
+	m.table.SparklineHeader = "CPU(Trend)"
+	if m.sparklineMode == SparklineMemory {
+		m.table.SparklineHeader = "Memory(Trend)"
+	}
+}
+
+// updateSparklines draws the trends of the rows from their history.
+func (m *Monitor) updateSparklines(rows int, row func(int) interface{}) {
+	if m.table.SparklineWidth == 0 {
+		return
+	}
+	h := m.historyOf(m.tableType())
+	sparklines := make([][]float64, rows)
+	for i := range sparklines {
+		key, _ := historyKey(row(i))
+		if m.sparklineMode == SparklineMemory {
+			sparklines[i] = h.get(key).Memory()
+		} else {
+			sparklines[i] = h.get(key).CPU()
+		}
+	}
+	m.table.Sparklines = sparklines
+}
@@ -0,0 +1,306 @@
+package ktop
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	statsapi "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+var (
+	rateTickFormatter  = func(v float64) string { return fmt.Sprintf("%vKi/s", int64(v)) }
+	countTickFormatter = func(v float64) string { return fmt.Sprintf("%v", int64(v)) }
+)
+
+const (
+	networkRxGraphTitle  = "Network Receive"
+	networkTxGraphTitle  = "Network Transmit"
+	filesystemGraphTitle = "Filesystem Usage"
+	inodesGraphTitle     = "Inodes Usage"
+)
+
+// statsKind is the stats of the kubelets recorded in the history.
+type statsKind int
+
+const (
+	// Ki/s
+	rxStats statsKind = iota
+	txStats
+	// Mi, or the percentage for nodes
+	fsStats
+	// count, or the percentage for nodes
+	inodesStats
+	numStatsKinds
+)
+
+// GraphMode decides what the graphs draw.
+type GraphMode int
+
+const (
+	GraphUsage GraphMode = iota
+	GraphNetwork
+	GraphFilesystem
+)
+
+func (g GraphMode) String() string {
+	switch g {
+	case GraphNetwork:
+		return "network"
+	case GraphFilesystem:
+		return "filesystem"
+	default:
+		return "usage"
+	}
+}
+
+// Next returns the mode to switch to from the mode.
+func (g GraphMode) Next() GraphMode {
+	return (g + 1) % (GraphFilesystem + 1)
+}
+
+// CycleGraph switches the graphs to the next mode,
+// which draws the stats of the kubelets if they are reported.
+func (m *Monitor) CycleGraph() {
+	if !m.withStats {
+		return
+	}
+	m.graphMode = m.graphMode.Next()
+	m.resetGraph()
+	m.refresh()
+}
+
+// currentGraphMode returns the mode of the graphs for the table,
+// which falls back to the usages if the table has no stats.
+func (m *Monitor) currentGraphMode() GraphMode {
+	if !m.withStats {
+		return GraphUsage
+	}
+	switch m.tableType() {
+	case resource.SummarizedType, resource.AllType, resource.NodeType:
+		return m.graphMode
+	default:
+		return GraphUsage
+	}
+}
+
+// networkSample is the cumulative traffic of a network to derive the rates.
+type networkSample struct {
+	rx   uint64
+	tx   uint64
+	time time.Time
+	rate *resource.NetworkStats
+}
+
+// applyStats sets the stats of the kubelets to the resources.
+func (m *Monitor) applyStats(snapshot *Snapshot, resources []*resource.Resource, summarizedResources []*resource.SummarizedResource, nodeResources []*resource.NodeResource) {
+	m.withStats = len(snapshot.Summaries) > 0
+	podStats := make(map[string]*statsapi.PodStats)
+	nodeStats := make(map[string]*statsapi.NodeStats)
+	for i := range snapshot.Summaries {
+		summary := &snapshot.Summaries[i]
+		nodeStats[summary.Node.NodeName] = &summary.Node
+		for j := range summary.Pods {
+			pod := &summary.Pods[j]
+			podStats[podKey(pod.PodRef.Namespace, pod.PodRef.Name)] = pod
+		}
+	}
+
+	samples := make(map[string]networkSample)
+	podNetworks := make(map[string]*resource.NetworkStats)
+	for _, r := range summarizedResources {
+		key := podKey(r.GetNamespace(), r.GetPodName())
+		stats, ok := podStats[key]
+		if !ok {
+			continue
+		}
+		network := m.networkRate(samples, key, stats.Network)
+		podNetworks[key] = network
+		r.SetStats(network, convertFsStats(stats.EphemeralStorage))
+	}
+	for _, r := range resources {
+		key := podKey(r.GetNamespace(), r.GetPodName())
+		stats, ok := podStats[key]
+		if !ok {
+			continue
+		}
+		for _, container := range stats.Containers {
+			if container.Name == r.GetContainerName() {
+				r.SetStats(podNetworks[key], convertFsStats(container.Rootfs), convertFsStats(container.Logs))
+				break
+			}
+		}
+	}
+	for _, r := range nodeResources {
+		stats, ok := nodeStats[r.GetNodeName()]
+		if !ok {
+			continue
+		}
+		r.SetStats(m.networkRate(samples, nodeKey(r.GetNodeName()), stats.Network), convertFsStats(stats.Fs))
+	}
+	m.networkSamples = samples
+}
+
+// networkRate derives the rates from the previous sample of the network,
+// which are unknown until the second sample.
+func (m *Monitor) networkRate(samples map[string]networkSample, key string, stats *statsapi.NetworkStats) *resource.NetworkStats {
+	if stats == nil || stats.RxBytes == nil || stats.TxBytes == nil {
+		return nil
+	}
+	current := networkSample{
+		rx:   *stats.RxBytes,
+		tx:   *stats.TxBytes,
+		time: stats.Time.Time,
+	}
+	prev, ok := m.networkSamples[key]
+	switch {
+	case ok && !current.time.After(prev.time):
+		// the kubelet has not updated the stats since the previous sample
+		current = prev
+	case ok && current.rx >= prev.rx && current.tx >= prev.tx:
+		elapsed := current.time.Sub(prev.time).Seconds()
+		current.rate = &resource.NetworkStats{
+			RxRate: float64(current.rx-prev.rx) / elapsed,
+			TxRate: float64(current.tx-prev.tx) / elapsed,
+		}
+	}
+	samples[key] = current
+	return current.rate
+}
+
+func convertFsStats(stats *statsapi.FsStats) *resource.FsStats {
+	if stats == nil {
+		return nil
+	}
+	value := func(v *uint64) uint64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	fs := &resource.FsStats{
+		UsedBytes:     value(stats.UsedBytes),
+		CapacityBytes: value(stats.CapacityBytes),
+		InodesUsed:    value(stats.InodesUsed),
+		Inodes:        value(stats.Inodes),
+	}
+	if stats.InodesUsed == nil && stats.Inodes != nil && stats.InodesFree != nil {
+		fs.InodesUsed = *stats.Inodes - *stats.InodesFree
+	}
+	return fs
+}
+
+// recordStats sets the latest stats to the history of each resource,
+// which are left as gaps if they are not reported.
+func (m *Monitor) recordStats() {
+	if !m.withStats {
+		return
+	}
+	for _, r := range m.summarizedResources {
+		m.podHistory.recordStats(podKey(r.GetNamespace(), r.GetPodName()),
+			statsSample(r.GetRxRate, r.GetTxRate, r.GetFsUsage, r.GetInodesUsage))
+	}
+	for _, r := range m.resources {
+		m.containerHistory.recordStats(containerKey(r.GetNamespace(), r.GetPodName(), r.GetContainerName()),
+			statsSample(r.GetRxRate, r.GetTxRate, r.GetFsUsage, r.GetInodesUsage))
+	}
+	for _, r := range m.nodeResources {
+		m.nodeHistory.recordStats(nodeKey(r.GetNodeName()),
+			statsSample(r.GetRxRate, r.GetTxRate, r.GetFsPercentage, r.GetInodesPercentage))
+	}
+}
+
+// statsSample returns the stats of the kinds in order, which are NaN if unknown.
+func statsSample(getters ...func() (float64, string, bool)) [numStatsKinds]float64 {
+	var sample [numStatsKinds]float64
+	for kind, get := range getters {
+		val, _, ok := get()
+		if !ok {
+			val = math.NaN()
+		}
+		sample[kind] = val
+	}
+	return sample
+}
+
+// updateStatsGraph draws the stats of the selected row instead of the usages.
+func (m *Monitor) updateStatsGraph() {
+	key, ok := historyKey(m.selected)
+	if !ok {
+		return
+	}
+	series := m.historyOf(m.tableType()).get(key)
+
+	var (
+		header  string
+		network interface {
+			GetRxRate() (float64, string, bool)
+			GetTxRate() (float64, string, bool)
+		}
+	)
+	switch r := m.selected.(type) {
+	case *resource.SummarizedResource:
+		header, network = fmt.Sprintf("Name: %v", r.GetPodName()), r
+	case *resource.Resource:
+		// the network is shared by the containers of the pod
+		header, network = fmt.Sprintf("Name: %v (network of pod %v)", r.GetContainerName(), r.GetPodName()), r
+	case *resource.NodeResource:
+		header, network = fmt.Sprintf("Name: %v", r.GetNodeName()), r
+	default:
+		return
+	}
+
+	if m.graphMode == GraphNetwork {
+		_, rxStr, _ := network.GetRxRate()
+		_, txStr, _ := network.GetTxRate()
+		setGraph(m.cpuGraph, header, series.Stats(rxStats), fmt.Sprintf("Rx: %v", rxStr), rateTickFormatter, nil)
+		setGraph(m.memGraph, header, series.Stats(txStats), fmt.Sprintf("Tx: %v", txStr), rateTickFormatter, nil)
+		return
+	}
+
+	switch r := m.selected.(type) {
+	case *resource.SummarizedResource:
+		_, fsStr, _ := r.GetFsUsage()
+		_, inodesStr, _ := r.GetInodesUsage()
+		limit, limitStr, ok := r.GetFsLimits()
+		setGraph(m.cpuGraph, header, series.Stats(fsStats), fmt.Sprintf("Usage: %v", fsStr), memoryTickFormatter,
+			graphLines{}.add(podLimitLabel, graphLimitColor, limit, limitStr, ok))
+		setGraph(m.memGraph, header, series.Stats(inodesStats), fmt.Sprintf("Used: %v", inodesStr), countTickFormatter, nil)
+	case *resource.Resource:
+		header = fmt.Sprintf("Name: %v", r.GetContainerName())
+		_, fsStr, _ := r.GetFsUsage()
+		_, inodesStr, _ := r.GetInodesUsage()
+		limit, limitStr, ok := r.GetFsLimits()
+		setGraph(m.cpuGraph, header, series.Stats(fsStats), fmt.Sprintf("Usage: %v", fsStr), memoryTickFormatter,
+			graphLines{}.add(containerLimitLabel, graphLimitColor, limit, limitStr, ok))
+		setGraph(m.memGraph, header, series.Stats(inodesStats), fmt.Sprintf("Used: %v", inodesStr), countTickFormatter, nil)
+	case *resource.NodeResource:
+		_, fsStr, _ := r.GetFsPercentage()
+		_, inodesStr, _ := r.GetInodesPercentage()
+		setGraph(m.cpuGraph, header, series.Stats(fsStats), fmt.Sprintf("Usage: %v", fsStr), percentageTickFormatter, nil)
+		setGraph(m.memGraph, header, series.Stats(inodesStats), fmt.Sprintf("Used: %v", inodesStr), percentageTickFormatter, nil)
+	}
+}
+
+// graphTitles returns the titles of the graphs in the mode.
+func (m *Monitor) graphTitles(mode GraphMode) (string, string) {
+	switch mode {
+	case GraphNetwork:
+		return networkRxGraphTitle, networkTxGraphTitle
+	case GraphFilesystem:
+		return filesystemGraphTitle, inodesGraphTitle
+	default:
+		return cpuGraphTitle, memoryGraphTitle
+	}
+}
+
+// statsGraphMax returns the top of the graphs of the stats in the fixed scale,
+// or 0 to fit the data.
+func (m *Monitor) statsGraphMax(mode GraphMode) float64 {
+	if mode == GraphFilesystem && m.tableType() == resource.NodeType {
+		return percentageGraphMax
+	}
+	return 0
+}
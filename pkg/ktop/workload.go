@@ -0,0 +1,63 @@
+package ktop
+
+import (
+	"fmt"
+
+	"github.com/ynqa/ktop/pkg/resource"
+)
+
+const (
+	workloadRequestLabel = "WorkloadRequests"
+	workloadLimitLabel   = "WorkloadLimits"
+)
+
+// aggregateWorkloads sums up the pods for each of their top-level controllers.
+func (m *Monitor) aggregateWorkloads(pods []*resource.SummarizedResource, controllers map[string]Workload) []*resource.WorkloadResource {
+	workloads := make([]*resource.WorkloadResource, 0)
+	index := make(map[string]*resource.WorkloadResource)
+	for _, pod := range pods {
+		controller, ok := controllers[podKey(pod.GetNamespace(), pod.GetPodName())]
+		if !ok {
+			// the pod may be deleted after listing
+			continue
+		}
+		key := workloadKey(pod.GetNamespace(), controller.Kind, controller.Name)
+		workload, ok := index[key]
+		if !ok {
+			workload = resource.NewWorkloadResource(pod.GetNamespace(), controller.Kind, controller.Name)
+			index[key] = workload
+			workloads = append(workloads, workload)
+		}
+		workload.Add(pod)
+	}
+	return workloads
+}
+
+func (m *Monitor) updateWorkloadGraph(workload *resource.WorkloadResource) {
+	series := m.workloadHistory.get(workloadKey(workload.GetNamespace(), workload.GetKind(), workload.GetWorkloadName()))
+	_, cpuUsageStr := workload.GetCpuUsage()
+	_, memUsageStr := workload.GetMemoryUsage()
+
+	header := fmt.Sprintf("Name: %v/%v (%v pods)",
+		workload.GetKind(), workload.GetWorkloadName(), workload.GetReplicas())
+
+	cpuLimit, cpuLimitStr, cok := workload.GetCpuLimits()
+	cpuRequest, cpuRequestStr, cpuRequestOk := workload.GetCpuRequests()
+	setGraph(m.cpuGraph, header, series.CPU(), fmt.Sprintf("Usage: %v", cpuUsageStr), cpuTickFormatter,
+		graphLines{}.
+			add(workloadRequestLabel, graphRequestColor, cpuRequest, cpuRequestStr, cpuRequestOk).
+			add(workloadLimitLabel, graphLimitColor, cpuLimit, cpuLimitStr, cok),
+	)
+
+	memLimit, memLimitStr, mok := workload.GetMemoryLimits()
+	memRequest, memRequestStr, memRequestOk := workload.GetMemoryRequests()
+	setGraph(m.memGraph, header, series.Memory(), fmt.Sprintf("Usage: %v", memUsageStr), memoryTickFormatter,
+		graphLines{}.
+			add(workloadRequestLabel, graphRequestColor, memRequest, memRequestStr, memRequestOk).
+			add(workloadLimitLabel, graphLimitColor, memLimit, memLimitStr, mok),
+	)
+}
+
+func workloadKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
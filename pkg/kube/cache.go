@@ -0,0 +1,224 @@
+package kube
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// how long to wait for the first list of the watched objects
+	cacheSyncTimeout = 30 * time.Second
+
+	// pods which still hold the resources of nodes
+	nonTerminatedPodSelector = "status.phase!=Succeeded,status.phase!=Failed"
+)
+
+// clusterCache keeps the objects of the cluster up to date by watches,
+// so that they can be read locally on each refresh.
+type clusterCache struct {
+	factory    informers.SharedInformerFactory
+	podLister  corelisters.PodLister
+	nodeLister corelisters.NodeLister
+	// listers of the controllers of the pods, which are nil if workloadsErr is set
+	replicaSetLister appslisters.ReplicaSetLister
+	jobLister        batchlisters.JobLister
+	workloadsErr     error
+	// lister of the quotas, which is nil if quotasErr is set
+	quotaLister corelisters.ResourceQuotaLister
+	quotasErr   error
+	stopCh      chan struct{}
+}
+
+func newClusterCache(clientset kubernetes.Interface, namespace string, selectors *Selectors) (*clusterCache, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		clientset,
+		0,
+		informers.WithNamespace(namespace),
+	)
+	// informers are registered to the factory before starting it,
+	// and the selectors are passed to the API server.
+	podInformer := factory.InformerFor(&corev1.Pod{},
+		func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return coreinformers.NewFilteredPodInformer(
+				client,
+				namespace,
+				resync,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+				func(options *metav1.ListOptions) {
+					options.LabelSelector = selectors.Pod.String()
+					options.FieldSelector = selectors.Field.String()
+				},
+			)
+		})
+	nodeInformer := factory.InformerFor(&corev1.Node{},
+		func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return coreinformers.NewFilteredNodeInformer(
+				client,
+				resync,
+				cache.Indexers{},
+				func(options *metav1.ListOptions) {
+					options.LabelSelector = selectors.Node.String()
+				},
+			)
+		})
+	c := &clusterCache{
+		factory:    factory,
+		podLister:  corelisters.NewPodLister(podInformer.GetIndexer()),
+		nodeLister: corelisters.NewNodeLister(nodeInformer.GetIndexer()),
+		stopCh:     make(chan struct{}),
+	}
+
+	// the controllers are only for the workload table
+	if err := checkList(
+		func(options metav1.ListOptions) error {
+			_, err := clientset.AppsV1().ReplicaSets(namespace).List(options)
+			return err
+		},
+		func(options metav1.ListOptions) error {
+			_, err := clientset.BatchV1().Jobs(namespace).List(options)
+			return err
+		},
+	); apierrors.IsForbidden(err) {
+		c.workloadsErr = err
+	} else if err != nil {
+		return nil, err
+	} else {
+		c.replicaSetLister = factory.Apps().V1().ReplicaSets().Lister()
+		c.jobLister = factory.Batch().V1().Jobs().Lister()
+	}
+	// the quotas are only for the namespace table
+	if err := checkList(
+		func(options metav1.ListOptions) error {
+			_, err := clientset.CoreV1().ResourceQuotas(namespace).List(options)
+			return err
+		},
+	); apierrors.IsForbidden(err) {
+		c.quotasErr = err
+	} else if err != nil {
+		return nil, err
+	} else {
+		c.quotaLister = factory.Core().V1().ResourceQuotas().Lister()
+	}
+	return c, nil
+}
+
+// checkList lists the objects in advance for the optional informers,
+// since an informer retries a forbidden list forever and never syncs.
+func checkList(lists ...func(options metav1.ListOptions) error) error {
+	for _, list := range lists {
+		if err := list(metav1.ListOptions{Limit: 1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clusterCache) start(timeout time.Duration) error {
+	c.factory.Start(c.stopCh)
+
+	timeoutCh := make(chan struct{})
+	timer := time.AfterFunc(timeout, func() {
+		close(timeoutCh)
+	})
+	defer timer.Stop()
+	for typ, synced := range c.factory.WaitForCacheSync(timeoutCh) {
+		if !synced {
+			return errors.Errorf("Failed to sync cache for %v", typ)
+		}
+	}
+	return nil
+}
+
+func (c *clusterCache) stop() {
+	close(c.stopCh)
+}
+
+// nodePodCache watches the pods of all namespaces scheduled to a node,
+// which is started only while the detail of the node is opened,
+// since the pods are not narrowed by the namespace and the selectors.
+type nodePodCache struct {
+	nodeName string
+	informer cache.SharedIndexInformer
+	stopCh   chan struct{}
+
+	mu sync.Mutex
+	// why the pods are not watched, e.g. forbidden
+	err error
+}
+
+func newNodePodCache(clientset kubernetes.Interface, nodeName string) *nodePodCache {
+	selector := fields.AndSelectors(
+		fields.OneTermEqualSelector("spec.nodeName", nodeName),
+		fields.ParseSelectorOrDie(nonTerminatedPodSelector),
+	).String()
+	c := &nodePodCache{
+		nodeName: nodeName,
+		informer: coreinformers.NewFilteredPodInformer(
+			clientset,
+			metav1.NamespaceAll,
+			0,
+			cache.Indexers{},
+			func(options *metav1.ListOptions) {
+				options.FieldSelector = selector
+			},
+		),
+		stopCh: make(chan struct{}),
+	}
+	go c.run(clientset, selector)
+	return c
+}
+
+func (c *nodePodCache) run(clientset kubernetes.Interface, selector string) {
+	if err := checkList(func(options metav1.ListOptions) error {
+		options.FieldSelector = selector
+		_, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(options)
+		return err
+	}); err != nil {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		return
+	}
+	c.informer.Run(c.stopCh)
+}
+
+// list returns the pods, or nil until they are synced.
+func (c *nodePodCache) list() (*corev1.PodList, error) {
+	c.mu.Lock()
+	err := c.err
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if !c.informer.HasSynced() {
+		return nil, nil
+	}
+	objs := c.informer.GetStore().List()
+	list := &corev1.PodList{
+		Items: make([]corev1.Pod, 0, len(objs)),
+	}
+	for _, obj := range objs {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			list.Items = append(list.Items, *pod)
+		}
+	}
+	return list, nil
+}
+
+func (c *nodePodCache) stop() {
+	close(c.stopCh)
+}
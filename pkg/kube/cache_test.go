@@ -0,0 +1,200 @@
+package kube
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testPod(namespace, name string, podLabels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    podLabels,
+		},
+	}
+}
+
+func testNode(name string, nodeLabels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: nodeLabels,
+		},
+	}
+}
+
+// listOptionsRecorder records the options of the lists of the resource,
+// since the fake clientset ignores the field selectors.
+type listOptionsRecorder struct {
+	mu      sync.Mutex
+	options []metav1.ListOptions
+}
+
+func (r *listOptionsRecorder) react(action k8stesting.Action) (bool, runtime.Object, error) {
+	if list, ok := action.(k8stesting.ListAction); ok {
+		restrictions := list.GetListRestrictions()
+		r.mu.Lock()
+		r.options = append(r.options, metav1.ListOptions{
+			LabelSelector: restrictions.Labels.String(),
+			FieldSelector: restrictions.Fields.String(),
+		})
+		r.mu.Unlock()
+	}
+	// falls through to the default reactor
+	return false, nil, nil
+}
+
+func (r *listOptionsRecorder) fieldSelectors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	selectors := make([]string, 0, len(r.options))
+	for _, options := range r.options {
+		selectors = append(selectors, options.FieldSelector)
+	}
+	return selectors
+}
+
+func forbidden(resource string) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", nil)
+	}
+}
+
+func startTestCache(t *testing.T, clientset *fake.Clientset, namespace string, selectors *Selectors) *clusterCache {
+	t.Helper()
+	c, err := newClusterCache(clientset, namespace, selectors)
+	if err != nil {
+		t.Fatalf("newClusterCache() error = %v", err)
+	}
+	if err := c.start(5 * time.Second); err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	t.Cleanup(c.stop)
+	return c
+}
+
+func TestClusterCache(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		testPod("default", "web-1", map[string]string{"app": "web"}),
+		testPod("default", "db-1", map[string]string{"app": "db"}),
+		testPod("other", "web-2", map[string]string{"app": "web"}),
+		testNode("node-1", map[string]string{"role": "worker"}),
+		testNode("node-2", map[string]string{"role": "master"}),
+	)
+	recorder := &listOptionsRecorder{}
+	clientset.PrependReactor("list", "pods", recorder.react)
+
+	selectors, err := NewSelectors("app=web", "status.phase=Running", "role=worker")
+	if err != nil {
+		t.Fatalf("NewSelectors() error = %v", err)
+	}
+	// start returns once the informers are synced
+	c := startTestCache(t, clientset, "default", selectors)
+
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		t.Fatalf("podLister.List() error = %v", err)
+	}
+	if len(pods) != 1 || pods[0].Namespace != "default" || pods[0].Name != "web-1" {
+		t.Errorf("pods = %v, want only default/web-1", pods)
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		t.Fatalf("nodeLister.List() error = %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].Name != "node-1" {
+		t.Errorf("nodes = %v, want only node-1", nodes)
+	}
+
+	var listed bool
+	for _, selector := range recorder.fieldSelectors() {
+		if selector == "status.phase=Running" {
+			listed = true
+		}
+	}
+	if !listed {
+		t.Errorf("field selectors of the lists = %v, want status.phase=Running", recorder.fieldSelectors())
+	}
+	if c.workloadsErr != nil || c.replicaSetLister == nil || c.jobLister == nil {
+		t.Errorf("workloads are unavailable: %v", c.workloadsErr)
+	}
+	if c.quotasErr != nil || c.quotaLister == nil {
+		t.Errorf("quotas are unavailable: %v", c.quotasErr)
+	}
+}
+
+func TestClusterCacheForbidden(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "jobs", forbidden("jobs"))
+	clientset.PrependReactor("list", "resourcequotas", forbidden("resourcequotas"))
+
+	selectors, err := NewSelectors("", "", "")
+	if err != nil {
+		t.Fatalf("NewSelectors() error = %v", err)
+	}
+	// the forbidden informers are not started, so that the others are synced
+	c := startTestCache(t, clientset, "default", selectors)
+	if !apierrors.IsForbidden(c.workloadsErr) || c.replicaSetLister != nil || c.jobLister != nil {
+		t.Errorf("workloadsErr = %v, want forbidden without listers", c.workloadsErr)
+	}
+	if !apierrors.IsForbidden(c.quotasErr) || c.quotaLister != nil {
+		t.Errorf("quotasErr = %v, want forbidden without lister", c.quotasErr)
+	}
+}
+
+func TestNodePodCache(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testPod("default", "web-1", nil))
+	recorder := &listOptionsRecorder{}
+	clientset.PrependReactor("list", "pods", recorder.react)
+
+	c := newNodePodCache(clientset, "node-1")
+	defer c.stop()
+	var (
+		list *corev1.PodList
+		err  error
+	)
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if list, err = c.list(); list != nil || err != nil {
+			break
+		}
+	}
+	if err != nil || list == nil {
+		t.Fatalf("list() = %v, %v, want synced pods", list, err)
+	}
+	// the requirements of the recorded selectors are sorted by parsing
+	want := fields.ParseSelectorOrDie("spec.nodeName=node-1," + nonTerminatedPodSelector).String()
+	for _, selector := range recorder.fieldSelectors() {
+		if selector != want {
+			t.Errorf("field selector of the list = %q, want %q", selector, want)
+		}
+	}
+}
+
+func TestNodePodCacheForbidden(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "pods", forbidden("pods"))
+
+	c := newNodePodCache(clientset, "node-1")
+	defer c.stop()
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err = c.list(); err != nil {
+			break
+		}
+	}
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("list() error = %v, want forbidden", err)
+	}
+}
@@ -1,30 +1,61 @@
 package kube
 
 import (
+	"encoding/json"
 	"io"
-
-	"github.com/pkg/errors"
+	"sync"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
-	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
-	"k8s.io/client-go/rest"
-	"k8s.io/kubernetes/pkg/kubectl/metricsutil"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
 	"k8s.io/metrics/pkg/apis/metrics"
-	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
-	"k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+// Selectors narrow the objects fetched from the cluster on the server side.
+type Selectors struct {
+	Pod   labels.Selector
+	Field fields.Selector
+	Node  labels.Selector
+}
+
+// NewSelectors parses the selectors in the syntax of the apimachinery.
+func NewSelectors(pod, field, node string) (*Selectors, error) {
+	podSelector, err := labels.Parse(pod)
+	if err != nil {
+		return nil, err
+	}
+	fieldSelector, err := fields.ParseSelector(field)
+	if err != nil {
+		return nil, err
+	}
+	nodeSelector, err := labels.Parse(node)
+	if err != nil {
+		return nil, err
+	}
+	return &Selectors{
+		Pod:   podSelector,
+		Field: fieldSelector,
+		Node:  nodeSelector,
+	}, nil
+}
+
 type KubeClients struct {
 	Flags         *genericclioptions.ConfigFlags
-	clientset     *kubernetes.Clientset
-	metricsClient metricsClient
+	Selectors     *Selectors
+	clientset     kubernetes.Interface
+	metricsClient MetricsClient
+	cache         *clusterCache
+
+	// pods on the node whose detail is opened, or nil
+	nodePodsMu sync.Mutex
+	nodePods   *nodePodCache
 }
 
-func NewKubeClients(flags *genericclioptions.ConfigFlags) (*KubeClients, error) {
+// NewKubeClients watches the objects of the cluster, and fetches the metrics by the client.
+func NewKubeClients(flags *genericclioptions.ConfigFlags, selectors *Selectors, metricsClient MetricsClient) (*KubeClients, error) {
 	config, err := flags.ToRESTConfig()
 	if err != nil {
 		return nil, err
@@ -33,138 +64,186 @@ func NewKubeClients(flags *genericclioptions.ConfigFlags) (*KubeClients, error)
 	if err != nil {
 		return nil, err
 	}
-	var metricsClient metricsClient
-	mergedErr := errors.New("Failed to create metrics client")
-	metricsClient, err = newMetricsServerClient(config)
+	return newKubeClients(flags, selectors, clientset, metricsClient)
+}
+
+func newKubeClients(flags *genericclioptions.ConfigFlags, selectors *Selectors, clientset kubernetes.Interface, metricsClient MetricsClient) (*KubeClients, error) {
+	cache, err := newClusterCache(clientset, *flags.Namespace, selectors)
 	if err != nil {
-		mergedErr = errors.Wrap(mergedErr, err.Error())
-		metricsClient, err = newHeapsterClient(clientset.CoreV1())
-		if err != nil {
-			mergedErr = errors.Wrap(mergedErr, err.Error())
-			return nil, mergedErr
-		}
+		return nil, err
+	}
+	if err := cache.start(cacheSyncTimeout); err != nil {
+		cache.stop()
+		return nil, err
 	}
 	return &KubeClients{
 		Flags:         flags,
+		Selectors:     selectors,
 		clientset:     clientset,
 		metricsClient: metricsClient,
+		cache:         cache,
 	}, nil
 }
 
-func (k *KubeClients) GetPodList(namespace string, labelSelector labels.Selector) (*corev1.PodList, error) {
-	return k.clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+// Close stops watching the objects of the cluster.
+func (k *KubeClients) Close() {
+	k.WatchNodePods("")
+	k.cache.stop()
 }
 
-func (k *KubeClients) GetPodLogs(namespace string, podName string) (string, error) {
-	// Tail size (number of lines)
-	count := int64(30)
-	follow := false
-	message := ""
-	podLogOptions := corev1.PodLogOptions{
-		Follow:    follow,
-		TailLines: &count,
-	}
-	podLogRequest := k.clientset.CoreV1().
-		Pods(namespace).
-		GetLogs(podName, &podLogOptions)
-	stream, err := podLogRequest.Stream()
+func (k *KubeClients) GetPodList(namespace string, labelSelector labels.Selector) (*corev1.PodList, error) {
+	pods, err := k.cache.podLister.Pods(namespace).List(labelSelector)
 	if err != nil {
-		return "failure1", err
-	}
-	defer stream.Close()
-	for {
-		buf := make([]byte, 2000)
-		numBytes, err := stream.Read(buf)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return "failure2", err
-		}
-		if numBytes == 0 {
-			continue
+		return nil, err
+	}
+	list := &corev1.PodList{
+		Items: make([]corev1.Pod, len(pods)),
+	}
+	for i, pod := range pods {
+		list.Items[i] = *pod
+	}
+	return list, nil
+}
+
+// WatchNodePods starts watching the pods of all namespaces scheduled to the node,
+// and stops watching the previous node. An empty name only stops it.
+func (k *KubeClients) WatchNodePods(nodeName string) {
+	k.nodePodsMu.Lock()
+	defer k.nodePodsMu.Unlock()
+	if k.nodePods != nil {
+		if k.nodePods.nodeName == nodeName {
+			return
 		}
-		message += string(buf[:numBytes])
+		k.nodePods.stop()
+		k.nodePods = nil
+	}
+	if nodeName != "" {
+		k.nodePods = newNodePodCache(k.clientset, nodeName)
 	}
-	return message, nil
 }
 
-func (k *KubeClients) GetPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
-	return k.metricsClient.getPodMetricsList(namespace, labelSelector)
+// WatchingNodePods returns the node whose pods are watched, or empty.
+func (k *KubeClients) WatchingNodePods() string {
+	k.nodePodsMu.Lock()
+	defer k.nodePodsMu.Unlock()
+	if k.nodePods == nil {
+		return ""
+	}
+	return k.nodePods.nodeName
 }
 
-func (k *KubeClients) GetNodeList(labelSelector labels.Selector) (*corev1.NodeList, error) {
-	return k.clientset.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+// GetNodePodList returns the pods of all namespaces which are scheduled to the node
+// and not terminated, or nil until they are synced after WatchNodePods.
+func (k *KubeClients) GetNodePodList(nodeName string) (*corev1.PodList, error) {
+	k.nodePodsMu.Lock()
+	nodePods := k.nodePods
+	k.nodePodsMu.Unlock()
+	if nodePods == nil || nodePods.nodeName != nodeName {
+		return nil, nil
+	}
+	return nodePods.list()
 }
 
-func (k *KubeClients) GetNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
-	return k.metricsClient.getNodeMetricsList(labelSelector)
+// FollowPodLogs opens a stream of the logs of the pod.
+// Closing the stream stops following the logs.
+func (k *KubeClients) FollowPodLogs(namespace string, podName string) (io.ReadCloser, error) {
+	// Tail size (number of lines)
+	count := int64(30)
+	podLogOptions := corev1.PodLogOptions{
+		Follow:    true,
+		TailLines: &count,
+	}
+	return k.clientset.CoreV1().
+		Pods(namespace).
+		GetLogs(podName, &podLogOptions).
+		Stream()
 }
 
-type metricsClient interface {
-	getPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error)
-	getNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error)
+func (k *KubeClients) GetPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
+	return k.metricsClient.GetPodMetricsList(namespace, labelSelector)
+}
+
+// GetNodeSummary reads the Summary API of the kubelet on the node through the proxy of the API server,
+// which reports the usages of the network and the filesystems in addition to cpu and memory.
+func (k *KubeClients) GetNodeSummary(nodeName string) (*statsapi.Summary, error) {
+	data, err := k.clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix("stats/summary").
+		DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	summary := &statsapi.Summary{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
 }
 
-type metricsServerClient struct {
-	*versioned.Clientset
+// QuotasError returns the reason why the quotas are not watched,
+// e.g. forbidden, or nil if GetResourceQuotaList is available.
+func (k *KubeClients) QuotasError() error {
+	return k.cache.quotasErr
 }
 
-func newMetricsServerClient(config *rest.Config) (*metricsServerClient, error) {
-	clientset, err := versioned.NewForConfig(config)
+func (k *KubeClients) GetResourceQuotaList(namespace string) (*corev1.ResourceQuotaList, error) {
+	if err := k.QuotasError(); err != nil {
+		return nil, err
+	}
+	quotas, err := k.cache.quotaLister.ResourceQuotas(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
-	return &metricsServerClient{
-		Clientset: clientset,
-	}, nil
+	list := &corev1.ResourceQuotaList{
+		Items: make([]corev1.ResourceQuota, len(quotas)),
+	}
+	for i, quota := range quotas {
+		list.Items[i] = *quota
+	}
+	return list, nil
 }
 
-func (c *metricsServerClient) getPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
-	list, err := c.MetricsV1beta1().PodMetricses(namespace).List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+func (k *KubeClients) GetNodeList(labelSelector labels.Selector) (*corev1.NodeList, error) {
+	nodes, err := k.cache.nodeLister.List(labelSelector)
 	if err != nil {
 		return nil, err
 	}
-	old := &metrics.PodMetricsList{}
-	if err := metricsv1beta1.Convert_v1beta1_PodMetricsList_To_metrics_PodMetricsList(list, old, nil); err != nil {
-		return nil, err
+	list := &corev1.NodeList{
+		Items: make([]corev1.Node, len(nodes)),
 	}
-	return old, nil
+	for i, node := range nodes {
+		list.Items[i] = *node
+	}
+	return list, nil
 }
 
-func (c *metricsServerClient) getNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
-	list, err := c.MetricsV1beta1().NodeMetricses().List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+// GetNodeMetricsList returns the metrics of the nodes,
+// whose names are resolved from the addresses if the metrics are named by them, e.g. by Prometheus.
+func (k *KubeClients) GetNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
+	list, err := k.metricsClient.GetNodeMetricsList(labelSelector)
 	if err != nil {
 		return nil, err
 	}
-	old := &metrics.NodeMetricsList{}
-	if err := metricsv1beta1.Convert_v1beta1_NodeMetricsList_To_metrics_NodeMetricsList(list, old, nil); err != nil {
+	nodes, err := k.cache.nodeLister.List(labels.Everything())
+	if err != nil {
 		return nil, err
 	}
-	return old, nil
-}
-
-type heapsterClient struct {
-	*metricsutil.HeapsterMetricsClient
-}
-
-func newHeapsterClient(svcClient corev1client.ServicesGetter) (*heapsterClient, error) {
-	heapster := metricsutil.NewHeapsterMetricsClient(
-		svcClient,
-		metricsutil.DefaultHeapsterNamespace,
-		metricsutil.DefaultHeapsterScheme,
-		metricsutil.DefaultHeapsterService,
-		metricsutil.DefaultHeapsterPort,
-	)
-	return &heapsterClient{
-		HeapsterMetricsClient: heapster,
-	}, nil
-}
-
-func (c *heapsterClient) getPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
-	return c.GetPodMetrics(namespace, "", false, labelSelector)
-}
-
-func (c *heapsterClient) getNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
-	return c.GetNodeMetrics("", labelSelector.String())
+	names := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			names[address.Address] = node.Name
+		}
+	}
+	// the names of the nodes win over the addresses
+	for _, node := range nodes {
+		names[node.Name] = node.Name
+	}
+	for i := range list.Items {
+		if name, ok := names[list.Items[i].Name]; ok {
+			list.Items[i].Name = name
+		}
+	}
+	return list, nil
 }
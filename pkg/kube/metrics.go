@@ -0,0 +1,123 @@
+package kube
+
+import (
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/kubectl/metricsutil"
+	"k8s.io/metrics/pkg/apis/metrics"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// MetricsSource is the backend to fetch metrics from.
+type MetricsSource string
+
+const (
+	MetricsServerSource MetricsSource = "metrics-server"
+	HeapsterSource      MetricsSource = "heapster"
+	PrometheusSource    MetricsSource = "prometheus"
+)
+
+// MetricsClient fetches the usages of the pods and the nodes from a backend.
+type MetricsClient interface {
+	// GetPodMetricsList returns the metrics of the pods in the namespace, or all namespaces if empty.
+	// The metrics of the pods which are not matched by the selector may be returned.
+	GetPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error)
+	// GetNodeMetricsList returns the metrics of the nodes.
+	// The metrics of the nodes which are not matched by the selector may be returned.
+	GetNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error)
+}
+
+// NewMetricsClient returns the client of the metrics source.
+func NewMetricsClient(flags *genericclioptions.ConfigFlags, source MetricsSource, prometheusURL string) (MetricsClient, error) {
+	if source == PrometheusSource {
+		return NewPrometheusClient(prometheusURL, nil)
+	}
+	config, err := flags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	switch source {
+	case MetricsServerSource:
+		return NewMetricsServerClient(config)
+	case HeapsterSource:
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return NewHeapsterClient(clientset.CoreV1())
+	default:
+		return nil, errors.Errorf("unknown metrics source: %v", source)
+	}
+}
+
+type metricsServerClient struct {
+	*versioned.Clientset
+}
+
+// NewMetricsServerClient fetches the metrics from the Metrics API served by metrics-server.
+func NewMetricsServerClient(config *rest.Config) (MetricsClient, error) {
+	clientset, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsServerClient{
+		Clientset: clientset,
+	}, nil
+}
+
+func (c *metricsServerClient) GetPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
+	list, err := c.MetricsV1beta1().PodMetricses(namespace).List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+	old := &metrics.PodMetricsList{}
+	if err := metricsv1beta1.Convert_v1beta1_PodMetricsList_To_metrics_PodMetricsList(list, old, nil); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+func (c *metricsServerClient) GetNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
+	list, err := c.MetricsV1beta1().NodeMetricses().List(metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+	old := &metrics.NodeMetricsList{}
+	if err := metricsv1beta1.Convert_v1beta1_NodeMetricsList_To_metrics_NodeMetricsList(list, old, nil); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+type heapsterClient struct {
+	*metricsutil.HeapsterMetricsClient
+}
+
+// NewHeapsterClient fetches the metrics from heapster, which is deprecated since Kubernetes 1.11.
+func NewHeapsterClient(svcClient corev1client.ServicesGetter) (MetricsClient, error) {
+	heapster := metricsutil.NewHeapsterMetricsClient(
+		svcClient,
+		metricsutil.DefaultHeapsterNamespace,
+		metricsutil.DefaultHeapsterScheme,
+		metricsutil.DefaultHeapsterService,
+		metricsutil.DefaultHeapsterPort,
+	)
+	return &heapsterClient{
+		HeapsterMetricsClient: heapster,
+	}, nil
+}
+
+func (c *heapsterClient) GetPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
+	return c.GetPodMetrics(namespace, "", namespace == metav1.NamespaceAll, labelSelector)
+}
+
+func (c *heapsterClient) GetNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
+	return c.GetNodeMetrics("", labelSelector.String())
+}
@@ -0,0 +1,261 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/metrics"
+)
+
+const (
+	// window of the rate of the cpu usage
+	prometheusRateWindow = time.Minute
+	prometheusTimeout    = 10 * time.Second
+
+	// series of cAdvisor scraped from the kubelets
+	cpuUsageMetric         = "container_cpu_usage_seconds_total"
+	memoryWorkingSetMetric = "container_memory_working_set_bytes"
+)
+
+// prometheusClient fetches the metrics of cAdvisor through the HTTP API of Prometheus.
+// The selectors are not applied since Prometheus does not know the labels of the objects,
+// and the metrics are joined with the pods and the nodes listed from the cluster instead.
+type prometheusClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewPrometheusClient queries the Prometheus at the url, e.g. http://localhost:9090,
+// with the http client, or a default one if nil.
+func NewPrometheusClient(prometheusURL string, client *http.Client) (MetricsClient, error) {
+	u, err := url.Parse(prometheusURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid prometheus url")
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("invalid prometheus url: %v", prometheusURL)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: prometheusTimeout}
+	}
+	return &prometheusClient{
+		url:    strings.TrimSuffix(prometheusURL, "/"),
+		client: client,
+	}, nil
+}
+
+// prometheusResponse is the response of the instant query in the vector type.
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			// unix time in seconds and the value in string
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusSample is a sample of the vector.
+type prometheusSample struct {
+	labels map[string]string
+	time   time.Time
+	value  float64
+}
+
+func (c *prometheusClient) query(q string) ([]prometheusSample, error) {
+	resp, err := c.client.Get(c.url + "/api/v1/query?" + url.Values{"query": {q}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrapf(err, "failed to query prometheus (%v)", resp.Status)
+	}
+	if body.Status != "success" {
+		return nil, errors.Errorf("failed to query prometheus: %v: %v", body.ErrorType, body.Error)
+	}
+	if body.Data.ResultType != "vector" {
+		return nil, errors.Errorf("unexpected result type of prometheus: %v", body.Data.ResultType)
+	}
+
+	samples := make([]prometheusSample, 0, len(body.Data.Result))
+	for _, r := range body.Data.Result {
+		ts, ok := r.Value[0].(float64)
+		if !ok {
+			return nil, errors.Errorf("invalid sample of prometheus: %v", r.Value)
+		}
+		str, ok := r.Value[1].(string)
+		if !ok {
+			return nil, errors.Errorf("invalid sample of prometheus: %v", r.Value)
+		}
+		val, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			continue
+		}
+		sec, frac := math.Modf(ts)
+		samples = append(samples, prometheusSample{
+			labels: r.Metric,
+			time:   time.Unix(int64(sec), int64(frac*1e9)),
+			value:  val,
+		})
+	}
+	return samples, nil
+}
+
+// containerMatchers selects the series of the containers,
+// except for the sums of the pods and the pause containers.
+func containerMatchers(namespace string) string {
+	matchers := []string{`container!=""`, `container!="POD"`, `pod!=""`}
+	if namespace != metav1.NamespaceAll {
+		matchers = append(matchers, fmt.Sprintf("namespace=%q", namespace))
+	}
+	return strings.Join(matchers, ",")
+}
+
+func (c *prometheusClient) GetPodMetricsList(namespace string, labelSelector labels.Selector) (*metrics.PodMetricsList, error) {
+	matchers := containerMatchers(namespace)
+	cpu, err := c.query(fmt.Sprintf("sum by (namespace, pod, container) (rate(%v{%v}[%v]))",
+		cpuUsageMetric, matchers, promDuration(prometheusRateWindow)))
+	if err != nil {
+		return nil, err
+	}
+	mem, err := c.query(fmt.Sprintf("sum by (namespace, pod, container) (%v{%v})",
+		memoryWorkingSetMetric, matchers))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metrics.PodMetricsList{}
+	pods := make(map[string]int)
+	containers := make(map[string]corev1.ResourceList)
+	get := func(s prometheusSample) corev1.ResourceList {
+		ns, pod, container := s.labels["namespace"], s.labels["pod"], s.labels["container"]
+		key := ns + "/" + pod + "/" + container
+		if usage, ok := containers[key]; ok {
+			return usage
+		}
+		i, ok := pods[ns+"/"+pod]
+		if !ok {
+			list.Items = append(list.Items, metrics.PodMetrics{
+				ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: pod},
+				Timestamp:  metav1.NewTime(s.time),
+				Window:     metav1.Duration{Duration: prometheusRateWindow},
+			})
+			i = len(list.Items) - 1
+			pods[ns+"/"+pod] = i
+		}
+		usage := emptyUsage()
+		list.Items[i].Containers = append(list.Items[i].Containers, metrics.ContainerMetrics{
+			Name:  container,
+			Usage: usage,
+		})
+		containers[key] = usage
+		return usage
+	}
+	for _, s := range cpu {
+		get(s)[corev1.ResourceCPU] = cpuQuantity(s.value)
+	}
+	for _, s := range mem {
+		get(s)[corev1.ResourceMemory] = memoryQuantity(s.value)
+	}
+	return list, nil
+}
+
+func (c *prometheusClient) GetNodeMetricsList(labelSelector labels.Selector) (*metrics.NodeMetricsList, error) {
+	// the root cgroup is the usage of the whole node
+	cpu, err := c.query(fmt.Sprintf(`sum by (node, instance) (rate(%v{id="/"}[%v]))`,
+		cpuUsageMetric, promDuration(prometheusRateWindow)))
+	if err != nil {
+		return nil, err
+	}
+	mem, err := c.query(fmt.Sprintf(`sum by (node, instance) (%v{id="/"})`, memoryWorkingSetMetric))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metrics.NodeMetricsList{}
+	nodes := make(map[string]int)
+	get := func(s prometheusSample) corev1.ResourceList {
+		name := nodeNameOf(s.labels)
+		i, ok := nodes[name]
+		if !ok {
+			list.Items = append(list.Items, metrics.NodeMetrics{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Timestamp:  metav1.NewTime(s.time),
+				Window:     metav1.Duration{Duration: prometheusRateWindow},
+				Usage:      emptyUsage(),
+			})
+			i = len(list.Items) - 1
+			nodes[name] = i
+		}
+		return list.Items[i].Usage
+	}
+	for _, s := range cpu {
+		get(s)[corev1.ResourceCPU] = cpuQuantity(s.value)
+	}
+	for _, s := range mem {
+		get(s)[corev1.ResourceMemory] = memoryQuantity(s.value)
+	}
+	return list, nil
+}
+
+// nodeNameOf returns the name of the node of the series, which is labeled as "node"
+// by the kube-prometheus, or "kubernetes_io_hostname" by the example config of Prometheus.
+// Otherwise it falls back to the host of the "instance" scraped from the kubelet,
+// which is usually the address of the node rather than the name,
+// and is resolved to the name by KubeClients.GetNodeMetricsList.
+func nodeNameOf(metric map[string]string) string {
+	for _, label := range []string{"node", "kubernetes_io_hostname"} {
+		if node := metric[label]; node != "" {
+			return node
+		}
+	}
+	instance := metric["instance"]
+	if host, _, err := net.SplitHostPort(instance); err == nil {
+		return host
+	}
+	return instance
+}
+
+func emptyUsage() corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    *kr.NewMilliQuantity(0, kr.DecimalSI),
+		corev1.ResourceMemory: *kr.NewQuantity(0, kr.BinarySI),
+	}
+}
+
+// cpuQuantity converts the cores to the quantity.
+func cpuQuantity(cores float64) kr.Quantity {
+	return *kr.NewMilliQuantity(int64(math.Round(cores*1000)), kr.DecimalSI)
+}
+
+func memoryQuantity(bytes float64) kr.Quantity {
+	return *kr.NewQuantity(int64(bytes), kr.BinarySI)
+}
+
+// promDuration formats the duration in the syntax of PromQL, e.g. 60s.
+func promDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
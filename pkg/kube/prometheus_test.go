@@ -0,0 +1,166 @@
+package kube
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// newTestPrometheus serves the responses of the queries which contain the keys.
+func newTestPrometheus(t *testing.T, responses map[string]string) MetricsClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query().Get("query")
+		for key, body := range responses {
+			if strings.Contains(q, key) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(body))
+				return
+			}
+		}
+		t.Errorf("unexpected query: %v", q)
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewPrometheusClient(server.URL+"/", nil)
+	if err != nil {
+		t.Fatalf("NewPrometheusClient() error = %v", err)
+	}
+	return client
+}
+
+func TestPrometheusQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []float64
+		wantErr string
+	}{
+		{
+			name: "vector",
+			body: `{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"a"},"value":[1580000000.5,"0.25"]},
+				{"metric":{"pod":"b"},"value":[1580000000.5,"1024"]}]}}`,
+			want: []float64{0.25, 1024},
+		},
+		{
+			name: "NaN and Inf are skipped",
+			body: `{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"a"},"value":[1580000000,"NaN"]},
+				{"metric":{"pod":"b"},"value":[1580000000,"+Inf"]},
+				{"metric":{"pod":"c"},"value":[1580000000,"-Inf"]},
+				{"metric":{"pod":"d"},"value":[1580000000,"1"]}]}}`,
+			want: []float64{1},
+		},
+		{
+			name:    "error",
+			body:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr: "bad_data: parse error",
+		},
+		{
+			name:    "not vector",
+			body:    `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantErr: "unexpected result type of prometheus: matrix",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestPrometheus(t, map[string]string{"up": tt.body})
+			samples, err := client.(*prometheusClient).query("up")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("query() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("query() error = %v", err)
+			}
+			if len(samples) != len(tt.want) {
+				t.Fatalf("query() = %v, want %v", samples, tt.want)
+			}
+			for i, s := range samples {
+				if s.value != tt.want[i] {
+					t.Errorf("value of sample %v = %v, want %v", i, s.value, tt.want[i])
+				}
+				if want := time.Unix(1580000000, 0); s.time.Truncate(time.Second) != want {
+					t.Errorf("time of sample %v = %v, want %v", i, s.time, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrometheusGetPodMetricsList(t *testing.T) {
+	client := newTestPrometheus(t, map[string]string{
+		cpuUsageMetric: `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"namespace":"default","pod":"web","container":"app"},"value":[1580000000,"0.5"]},
+			{"metric":{"namespace":"default","pod":"web","container":"proxy"},"value":[1580000000,"0.1"]}]}}`,
+		memoryWorkingSetMetric: `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"namespace":"default","pod":"web","container":"app"},"value":[1580000000,"1048576"]}]}}`,
+	})
+	list, err := client.GetPodMetricsList(metav1.NamespaceDefault, labels.Everything())
+	if err != nil {
+		t.Fatalf("GetPodMetricsList() error = %v", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("pods = %v, want only default/web", list.Items)
+	}
+	pod := list.Items[0]
+	if pod.Namespace != "default" || pod.Name != "web" || len(pod.Containers) != 2 {
+		t.Fatalf("pod = %v, want default/web with 2 containers", pod)
+	}
+	// the cpu and the memory of a container are joined
+	for _, tt := range []struct {
+		container string
+		cpu       int64
+		memory    int64
+	}{
+		{container: "app", cpu: 500, memory: 1048576},
+		{container: "proxy", cpu: 100, memory: 0},
+	} {
+		var found bool
+		for _, c := range pod.Containers {
+			if c.Name != tt.container {
+				continue
+			}
+			found = true
+			cpu, memory := c.Usage[corev1.ResourceCPU], c.Usage[corev1.ResourceMemory]
+			if cpu.MilliValue() != tt.cpu || memory.Value() != tt.memory {
+				t.Errorf("usage of %v = %v, %v, want %vm, %v", tt.container, cpu.String(), memory.String(), tt.cpu, tt.memory)
+			}
+		}
+		if !found {
+			t.Errorf("container %v is not found in %v", tt.container, pod.Containers)
+		}
+	}
+}
+
+func TestNodeNameOf(t *testing.T) {
+	tests := []struct {
+		metric map[string]string
+		want   string
+	}{
+		{metric: map[string]string{"node": "node-1", "instance": "10.0.0.1:10250"}, want: "node-1"},
+		{metric: map[string]string{"kubernetes_io_hostname": "node-1", "instance": "10.0.0.1:10250"}, want: "node-1"},
+		{metric: map[string]string{"instance": "10.0.0.1:10250"}, want: "10.0.0.1"},
+		{metric: map[string]string{"instance": "[fd00::1]:10250"}, want: "fd00::1"},
+		{metric: map[string]string{"instance": "node-1"}, want: "node-1"},
+	}
+	for _, tt := range tests {
+		if got := nodeNameOf(tt.metric); got != tt.want {
+			t.Errorf("nodeNameOf(%v) = %v, want %v", tt.metric, got, tt.want)
+		}
+	}
+}
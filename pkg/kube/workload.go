@@ -0,0 +1,59 @@
+package kube
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// kinds of workloads
+	PodKind         = "Pod"
+	ReplicaSetKind  = "ReplicaSet"
+	DeploymentKind  = "Deployment"
+	StatefulSetKind = "StatefulSet"
+	DaemonSetKind   = "DaemonSet"
+	JobKind         = "Job"
+	CronJobKind     = "CronJob"
+)
+
+// WorkloadsError returns the reason why the controllers of the pods are not watched,
+// e.g. forbidden, or nil if GetPodWorkload is available.
+func (k *KubeClients) WorkloadsError() error {
+	return k.cache.workloadsErr
+}
+
+// GetPodWorkload resolves the owner references of the pod up to the top-level
+// controller, and returns its kind and name. A pod without controllers is
+// the workload by itself.
+func (k *KubeClients) GetPodWorkload(namespace string, podName string) (string, string, error) {
+	if err := k.WorkloadsError(); err != nil {
+		return "", "", err
+	}
+	pod, err := k.cache.podLister.Pods(namespace).Get(podName)
+	if err != nil {
+		return "", "", err
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return PodKind, pod.Name, nil
+	}
+
+	switch owner.Kind {
+	case ReplicaSetKind:
+		rs, err := k.cache.replicaSetLister.ReplicaSets(namespace).Get(owner.Name)
+		if err != nil {
+			break
+		}
+		if parent := metav1.GetControllerOf(rs); parent != nil && parent.Kind == DeploymentKind {
+			return parent.Kind, parent.Name, nil
+		}
+	case JobKind:
+		job, err := k.cache.jobLister.Jobs(namespace).Get(owner.Name)
+		if err != nil {
+			break
+		}
+		if parent := metav1.GetControllerOf(job); parent != nil && parent.Kind == CronJobKind {
+			return parent.Kind, parent.Name, nil
+		}
+	}
+	return owner.Kind, owner.Name, nil
+}
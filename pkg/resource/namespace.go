@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+// quotaStatus is the hard limit of a ResourceQuota and how much of it is used.
+type quotaStatus struct {
+	hard kr.Quantity
+	used kr.Quantity
+}
+
+type NamespaceResource struct {
+	name     string
+	pods     int
+	usage    corev1.ResourceList
+	limits   corev1.ResourceList
+	requests corev1.ResourceList
+	// quotas for cpu/memory
+	requestsQuota map[corev1.ResourceName]quotaStatus
+	limitsQuota   map[corev1.ResourceName]quotaStatus
+}
+
+func NewNamespaceResource(name string, quotas []corev1.ResourceQuota) *NamespaceResource {
+	n := &NamespaceResource{
+		name:          name,
+		usage:         make(corev1.ResourceList),
+		limits:        make(corev1.ResourceList),
+		requests:      make(corev1.ResourceList),
+		requestsQuota: make(map[corev1.ResourceName]quotaStatus),
+		limitsQuota:   make(map[corev1.ResourceName]quotaStatus),
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		requestsNames := []corev1.ResourceName{corev1.ResourceName("requests." + name), name}
+		limitsNames := []corev1.ResourceName{corev1.ResourceName("limits." + name)}
+		if status, ok := tightestQuota(quotas, requestsNames); ok {
+			n.requestsQuota[name] = status
+		}
+		if status, ok := tightestQuota(quotas, limitsNames); ok {
+			n.limitsQuota[name] = status
+		}
+	}
+	return n
+}
+
+// tightestQuota returns the quota with the smallest hard limit in the quotas
+// of the namespace, which restricts it in effect.
+func tightestQuota(quotas []corev1.ResourceQuota, names []corev1.ResourceName) (quotaStatus, bool) {
+	var (
+		tightest quotaStatus
+		found    bool
+	)
+	for _, quota := range quotas {
+		for _, name := range names {
+			hard, ok := quota.Status.Hard[name]
+			if !ok {
+				continue
+			}
+			if !found || hard.Cmp(tightest.hard) < 0 {
+				tightest = quotaStatus{hard: hard, used: quota.Status.Used[name]}
+				found = true
+			}
+		}
+	}
+	return tightest, found
+}
+
+// Add sums up the pod in the namespace.
+// Limits and requests are summed for the pods which define them,
+// except for the terminated pods which are not charged to the quotas either.
+func (n *NamespaceResource) Add(pod *SummarizedResource) {
+	terminated := pod.status.phase == corev1.PodSucceeded || pod.status.phase == corev1.PodFailed
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		addQuantity(n.usage, pod.usage, name, true)
+		if !terminated {
+			addQuantity(n.limits, pod.limits, name, true)
+			addQuantity(n.requests, pod.requests, name, true)
+		}
+	}
+	n.pods++
+}
+
+func (n *NamespaceResource) GetNamespace() string {
+	return n.name
+}
+
+func (n *NamespaceResource) GetPods() int {
+	return n.pods
+}
+
+// HasUsage returns whether the metrics of any pod in the namespace are reported.
+func (n *NamespaceResource) HasUsage() bool {
+	_, ok := n.usage[corev1.ResourceCPU]
+	return ok
+}
+
+func (n *NamespaceResource) GetCpuUsage() (float64, string) {
+	return GetResourceValue(n.usage, corev1.ResourceCPU),
+		GetResourceValueString(n.usage, corev1.ResourceCPU)
+}
+
+func (n *NamespaceResource) GetMemoryUsage() (float64, string) {
+	return GetResourceValue(n.usage, corev1.ResourceMemory),
+		GetResourceValueString(n.usage, corev1.ResourceMemory)
+}
+
+// GetCpuLimitsQuota returns the hard limit of the quota for cpu limits.
+func (n *NamespaceResource) GetCpuLimitsQuota() (float64, string, bool) {
+	return n.getQuotaHard(n.limitsQuota, corev1.ResourceCPU)
+}
+
+// GetMemoryLimitsQuota returns the hard limit of the quota for memory limits.
+func (n *NamespaceResource) GetMemoryLimitsQuota() (float64, string, bool) {
+	return n.getQuotaHard(n.limitsQuota, corev1.ResourceMemory)
+}
+
+// GetCpuRequestsQuota returns the hard limit of the quota for cpu requests.
+func (n *NamespaceResource) GetCpuRequestsQuota() (float64, string, bool) {
+	return n.getQuotaHard(n.requestsQuota, corev1.ResourceCPU)
+}
+
+// GetMemoryRequestsQuota returns the hard limit of the quota for memory requests.
+func (n *NamespaceResource) GetMemoryRequestsQuota() (float64, string, bool) {
+	return n.getQuotaHard(n.requestsQuota, corev1.ResourceMemory)
+}
+
+func (n *NamespaceResource) getQuotaHard(quotas map[corev1.ResourceName]quotaStatus, name corev1.ResourceName) (float64, string, bool) {
+	status, ok := quotas[name]
+	if !ok {
+		return 0, "-", false
+	}
+	lst := corev1.ResourceList{name: status.hard}
+	return GetResourceValue(lst, name), GetResourceValueString(lst, name), true
+}
+
+// limitsQuotaHard returns the hard limits of the quotas for limits.
+func (n *NamespaceResource) limitsQuotaHard() corev1.ResourceList {
+	lst := make(corev1.ResourceList)
+	for name, status := range n.limitsQuota {
+		lst[name] = status.hard
+	}
+	return lst
+}
+
+// quotaString formats the quota as "used/hard".
+func quotaString(quotas map[corev1.ResourceName]quotaStatus, name corev1.ResourceName) string {
+	status, ok := quotas[name]
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%v/%v",
+		GetResourceValueString(corev1.ResourceList{name: status.used}, name),
+		GetResourceValueString(corev1.ResourceList{name: status.hard}, name),
+	)
+}
+
+func (n *NamespaceResource) compare(other *NamespaceResource, sortType SortType) int {
+	switch sortType {
+	case ByCPUUsage:
+		return compareValue(n.usage, other.usage, corev1.ResourceCPU)
+	case ByCPULimit:
+		return compareValue(n.limits, other.limits, corev1.ResourceCPU)
+	case ByCPURequest:
+		return compareValue(n.requests, other.requests, corev1.ResourceCPU)
+	case ByMemoryUsage:
+		return compareValue(n.usage, other.usage, corev1.ResourceMemory)
+	case ByMemoryLimit:
+		return compareValue(n.limits, other.limits, corev1.ResourceMemory)
+	case ByMemoryRequest:
+		return compareValue(n.requests, other.requests, corev1.ResourceMemory)
+	default:
+		return compareString(n.name, other.name)
+	}
+}
+
+// header: "NAMESPACE", "PODS", "CPU(U)", "CPU(L)", "CPU(R)", "CPU(LQ)", "CPU(RQ)", "Memory(U)", "Memory(L)", "Memory(R)", "Memory(LQ)", "Memory(RQ)"
+func (n *NamespaceResource) toRow() []string {
+	return []string{
+		n.name,
+		fmt.Sprintf("%v", n.pods),
+		GetResourceValueString(n.usage, corev1.ResourceCPU),
+		GetResourceValueString(n.limits, corev1.ResourceCPU),
+		GetResourceValueString(n.requests, corev1.ResourceCPU),
+		quotaString(n.limitsQuota, corev1.ResourceCPU),
+		quotaString(n.requestsQuota, corev1.ResourceCPU),
+		GetResourceValueString(n.usage, corev1.ResourceMemory),
+		GetResourceValueString(n.limits, corev1.ResourceMemory),
+		GetResourceValueString(n.requests, corev1.ResourceMemory),
+		quotaString(n.limitsQuota, corev1.ResourceMemory),
+		quotaString(n.requestsQuota, corev1.ResourceMemory),
+	}
+}
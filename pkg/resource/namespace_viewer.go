@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"image"
+	"sort"
+	"time"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+var (
+	namespaceTitle  = "⎈ Namespace ⎈"
+	namespaceHeader = []string{
+		"NAMESPACE", "PODS",
+		"CPU(U)", "CPU(L)", "CPU(R)", "CPU(LQ)", "CPU(RQ)",
+		"Memory(U)", "Memory(L)", "Memory(R)", "Memory(LQ)", "Memory(RQ)",
+	}
+	namespaceWidthFn = func(rect image.Rectangle, maxLen int) []int {
+		nameWidth := IntMax(30, IntMin(rect.Dx()-130, maxLen+indentSize))
+		return []int{nameWidth, 6, 10, 10, 10, 15, 15, 10, 10, 10, 16, 16}
+	}
+	namespaceSortTypes = []SortType{
+		ByName,
+		ByCPUUsage, ByCPULimit, ByCPURequest,
+		ByMemoryUsage, ByMemoryLimit, ByMemoryRequest,
+	}
+	namespaceSortColumns = []int{0, 2, 3, 4, 7, 8, 9}
+	// columns of cpu and memory usages, which are compared with the quotas
+	namespaceUsageColumns = []int{2, 7}
+)
+
+func AsNamespaceTableViewer(resources []*NamespaceResource, sortType SortType, order SortOrder) ResourceTableViewer {
+	return &namespaceTableViewer{
+		resources: resources,
+		sortType:  sortType,
+		order:     order,
+	}
+}
+
+type namespaceTableViewer struct {
+	resources []*NamespaceResource
+	sortType  SortType
+	order     SortOrder
+}
+
+func (v *namespaceTableViewer) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
+	rows := make([][]string, len(v.resources))
+	var maxLen int
+	for i, r := range v.resources {
+		rows[i] = r.toRow()
+		maxLen = IntMax(maxLen, len(rows[i][0]))
+	}
+	title, header, widths :=
+		namespaceTitle, namespaceHeader, namespaceWidthFn(rect, maxLen)
+
+	if len(v.resources) == 0 {
+		header = emptyHeader
+		widths = emptyWidthFn(rect)
+		rows = emptyRows
+	}
+	return title, header, widths, rows
+}
+
+func (v *namespaceTableViewer) GetRecords(timestamp time.Time) ([]string, []Record) {
+	records := make([]Record, len(v.resources))
+	for i, r := range v.resources {
+		records[i] = r.toRecord(timestamp)
+	}
+	return namespaceRecordHeader, records
+}
+
+func (v *namespaceTableViewer) GetSortColumn() int {
+	if len(v.resources) == 0 {
+		return -1
+	}
+	return sortColumnOf(v.sortType, namespaceSortTypes, namespaceSortColumns)
+}
+
+func (v *namespaceTableViewer) GetRowStates() []RowState {
+	return nil
+}
+
+func (v *namespaceTableViewer) GetCellStates(thresholds Thresholds) [][]RowState {
+	states := make([][]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = thresholds.cellStates(len(namespaceHeader), r.usage, r.limitsQuotaHard(), namespaceUsageColumns)
+	}
+	return states
+}
+
+func (v *namespaceTableViewer) SortRows() {
+	sort.SliceStable(v.resources, func(i, j int) bool {
+		a, b := v.resources[i], v.resources[j]
+		return less(v.order, a.compare(b, v.sortType), a.compare(b, ByName))
+	})
+}
@@ -1,25 +1,115 @@
 package resource
 
 import (
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/metrics/pkg/apis/metrics"
 
 	. "github.com/ynqa/ktop/pkg/util"
 )
 
+var (
+	// conditions shown in the details of nodes
+	nodeConditionTypes = []corev1.NodeConditionType{
+		corev1.NodeReady,
+		corev1.NodeMemoryPressure,
+		corev1.NodeDiskPressure,
+		corev1.NodePIDPressure,
+	}
+)
+
 type NodeResource struct {
 	nodeName    string
 	capacity    corev1.ResourceList
 	allocatable corev1.ResourceList
 	usage       corev1.ResourceList
+
+	// details
+	conditions     map[corev1.NodeConditionType]corev1.ConditionStatus
+	taints         []corev1.Taint
+	unschedulable  bool
+	kubeletVersion string
+	// pods scheduled to the node and the sums of their requests/limits,
+	// which are only listed for the node whose detail is opened
+	pods              int
+	podsListed        bool
+	podsErr           string
+	allocatedRequests corev1.ResourceList
+	allocatedLimits   corev1.ResourceList
+
+	// stats of the kubelet, where fs is the filesystem of the kubelet and the logs, i.e. nodefs
+	network *NetworkStats
+	fs      *FsStats
 }
 
 func NewNodeResource(n corev1.Node, nm metrics.NodeMetrics) *NodeResource {
-	return &NodeResource{
-		nodeName:    nm.Name,
-		capacity:    n.Status.Capacity,
-		allocatable: n.Status.Allocatable,
-		usage:       nm.Usage,
+	r := &NodeResource{
+		nodeName:       n.Name,
+		capacity:       n.Status.Capacity,
+		allocatable:    n.Status.Allocatable,
+		usage:          nm.Usage,
+		conditions:     make(map[corev1.NodeConditionType]corev1.ConditionStatus),
+		taints:         n.Spec.Taints,
+		unschedulable:  n.Spec.Unschedulable,
+		kubeletVersion: n.Status.NodeInfo.KubeletVersion,
+	}
+	for _, cond := range n.Status.Conditions {
+		r.conditions[cond.Type] = cond.Status
+	}
+	return r
+}
+
+// SetPods sums up the pods of all namespaces which are scheduled to the node and not terminated.
+func (r *NodeResource) SetPods(pods []corev1.Pod) {
+	r.pods = len(pods)
+	r.podsListed = true
+	r.allocatedRequests = corev1.ResourceList{corev1.ResourceCPU: kr.Quantity{}, corev1.ResourceMemory: kr.Quantity{}}
+	r.allocatedLimits = corev1.ResourceList{corev1.ResourceCPU: kr.Quantity{}, corev1.ResourceMemory: kr.Quantity{}}
+	for _, pod := range pods {
+		requests, limits := podRequestsAndLimits(pod)
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			addQuantity(r.allocatedRequests, requests, name, true)
+			addQuantity(r.allocatedLimits, limits, name, true)
+		}
+	}
+}
+
+// SetPodsError tells why the pods on the node can not be listed, e.g. forbidden.
+func (r *NodeResource) SetPodsError(reason string) {
+	r.podsErr = reason
+}
+
+// podRequestsAndLimits returns the resources reserved by the pod in the same way as kubectl,
+// i.e. the larger of the sum of the containers and any init container.
+func podRequestsAndLimits(pod corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := make(corev1.ResourceList), make(corev1.ResourceList)
+	for _, c := range pod.Spec.Containers {
+		for name, val := range c.Resources.Requests {
+			total := requests[name]
+			total.Add(val)
+			requests[name] = total
+		}
+		for name, val := range c.Resources.Limits {
+			total := limits[name]
+			total.Add(val)
+			limits[name] = total
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		maxResourceList(requests, c.Resources.Requests)
+		maxResourceList(limits, c.Resources.Limits)
+	}
+	return requests, limits
+}
+
+func maxResourceList(lst, other corev1.ResourceList) {
+	for name, val := range other {
+		if current, ok := lst[name]; !ok || val.Cmp(current) > 0 {
+			lst[name] = val.DeepCopy()
+		}
 	}
 }
 
@@ -27,6 +117,58 @@ func (r *NodeResource) GetNodeName() string {
 	return r.nodeName
 }
 
+// HasUsage returns whether the metrics of the node are reported.
+func (r *NodeResource) HasUsage() bool {
+	_, ok := r.usage[corev1.ResourceCPU]
+	return ok
+}
+
+func (r *NodeResource) GetCpuUsage() (float64, string) {
+	return GetResourceValue(r.usage, corev1.ResourceCPU),
+		GetResourceValueString(r.usage, corev1.ResourceCPU)
+}
+
+func (r *NodeResource) GetMemoryUsage() (float64, string) {
+	return GetResourceValue(r.usage, corev1.ResourceMemory),
+		GetResourceValueString(r.usage, corev1.ResourceMemory)
+}
+
+func (r *NodeResource) GetCpuAllocatable() (float64, string, bool) {
+	return getOptionalValue(r.allocatable, corev1.ResourceCPU)
+}
+
+func (r *NodeResource) GetMemoryAllocatable() (float64, string, bool) {
+	return getOptionalValue(r.allocatable, corev1.ResourceMemory)
+}
+
+// SetStats sets the stats reported by the kubelet.
+func (r *NodeResource) SetStats(network *NetworkStats, fs *FsStats) {
+	r.network = network
+	r.fs = fs
+}
+
+func (r *NodeResource) hasStats() bool {
+	return r.network != nil || r.fs != nil
+}
+
+func (r *NodeResource) GetRxRate() (float64, string, bool) {
+	return r.network.rxRate()
+}
+
+func (r *NodeResource) GetTxRate() (float64, string, bool) {
+	return r.network.txRate()
+}
+
+// GetFsPercentage returns the usage of the nodefs against its capacity,
+// which causes the disk pressure when it is running out.
+func (r *NodeResource) GetFsPercentage() (float64, string, bool) {
+	return r.fs.usedPercentage()
+}
+
+func (r *NodeResource) GetInodesPercentage() (float64, string, bool) {
+	return r.fs.inodesPercentage()
+}
+
 func (r *NodeResource) GetCpuUsagePercentage() (float64, string) {
 	return GetResourcePercentage(*r.usage.Cpu(), *r.allocatable.Cpu()),
 		GetResourcePercentageString(*r.usage.Cpu(), *r.allocatable.Cpu())
@@ -37,15 +179,183 @@ func (r *NodeResource) GetMemoryUsagePercentage() (float64, string) {
 		GetResourcePercentageString(*r.usage.Memory(), *r.allocatable.Memory())
 }
 
-// header: "NODE", "CPU(C)", "CPU(A)", "CPU(U)", "%CPU", "Memory(C)", "Memory(A)", "Memory(U)", "%Memory",
+// GetCpuRequestsPercentage returns the sum of cpu requests of the pods against the allocatable.
+func (r *NodeResource) GetCpuRequestsPercentage() (float64, string) {
+	return r.getAllocatedPercentage(r.allocatedRequests, corev1.ResourceCPU)
+}
+
+// GetCpuLimitsPercentage returns the sum of cpu limits of the pods against the allocatable.
+func (r *NodeResource) GetCpuLimitsPercentage() (float64, string) {
+	return r.getAllocatedPercentage(r.allocatedLimits, corev1.ResourceCPU)
+}
+
+// GetMemoryRequestsPercentage returns the sum of memory requests of the pods against the allocatable.
+func (r *NodeResource) GetMemoryRequestsPercentage() (float64, string) {
+	return r.getAllocatedPercentage(r.allocatedRequests, corev1.ResourceMemory)
+}
+
+// GetMemoryLimitsPercentage returns the sum of memory limits of the pods against the allocatable.
+func (r *NodeResource) GetMemoryLimitsPercentage() (float64, string) {
+	return r.getAllocatedPercentage(r.allocatedLimits, corev1.ResourceMemory)
+}
+
+func (r *NodeResource) getAllocatedPercentage(allocated corev1.ResourceList, name corev1.ResourceName) (float64, string) {
+	val, ok := allocated[name]
+	available, aok := r.allocatable[name]
+	if !ok || !aok || available.IsZero() {
+		return 0, "-"
+	}
+	return GetResourcePercentage(val, available), GetResourcePercentageString(val, available)
+}
+
+// GetStatus returns the status of the node in the same way as kubectl.
+func (r *NodeResource) GetStatus() string {
+	status := "NotReady"
+	switch r.conditions[corev1.NodeReady] {
+	case corev1.ConditionTrue:
+		status = "Ready"
+	case "":
+		status = "Unknown"
+	}
+	if r.unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+func (r *NodeResource) podsString() string {
+	if !r.podsListed {
+		return "-"
+	}
+	allocatable, ok := r.allocatable[corev1.ResourcePods]
+	if !ok {
+		return fmt.Sprintf("%v", r.pods)
+	}
+	return fmt.Sprintf("%v/%v", r.pods, allocatable.Value())
+}
+
+// Detail describes the node like "kubectl describe node".
+func (r *NodeResource) Detail() string {
+	conditions := make([]string, len(nodeConditionTypes))
+	for i, typ := range nodeConditionTypes {
+		status, ok := r.conditions[typ]
+		if !ok {
+			status = corev1.ConditionUnknown
+		}
+		conditions[i] = fmt.Sprintf("%v=%v", typ, status)
+	}
+	taints := make([]string, len(r.taints))
+	for i, taint := range r.taints {
+		taints[i] = taint.ToString()
+	}
+	if len(taints) == 0 {
+		taints = []string{"<none>"}
+	}
+	pods := r.podsString()
+	switch {
+	case r.podsErr != "":
+		pods = fmt.Sprintf("%v (unavailable: %v)", pods, r.podsErr)
+	case !r.podsListed:
+		pods = fmt.Sprintf("%v (loading)", pods)
+	}
+	allocated := func(name corev1.ResourceName) string {
+		_, requests := r.getAllocatedPercentage(r.allocatedRequests, name)
+		_, limits := r.getAllocatedPercentage(r.allocatedLimits, name)
+		return fmt.Sprintf("requests %v (%v), limits %v (%v)",
+			GetResourceValueString(r.allocatedRequests, name), requests,
+			GetResourceValueString(r.allocatedLimits, name), limits,
+		)
+	}
+	lines := []string{
+		fmt.Sprintf("Status:      %v", r.GetStatus()),
+		fmt.Sprintf("Kubelet:     %v", r.kubeletVersion),
+		fmt.Sprintf("Conditions:  %v", strings.Join(conditions, " ")),
+		fmt.Sprintf("Taints:      %v", strings.Join(taints, " ")),
+		fmt.Sprintf("Pods:        %v", pods),
+		fmt.Sprintf("CPU:         %v", allocated(corev1.ResourceCPU)),
+		fmt.Sprintf("Memory:      %v", allocated(corev1.ResourceMemory)),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rowState tells nodes which are not ready, under pressure or cordoned.
+func (r *NodeResource) rowState() RowState {
+	if r.conditions[corev1.NodeReady] != corev1.ConditionTrue {
+		return RowError
+	}
+	if r.unschedulable {
+		return RowWarning
+	}
+	for _, typ := range nodeConditionTypes[1:] {
+		if r.conditions[typ] == corev1.ConditionTrue {
+			return RowWarning
+		}
+	}
+	return RowNormal
+}
+
+func (r *NodeResource) compare(other *NodeResource, sortType SortType) int {
+	switch sortType {
+	case ByCPUUsage:
+		return compareValue(r.usage, other.usage, corev1.ResourceCPU)
+	case ByCPUPercentage:
+		x, _ := r.GetCpuUsagePercentage()
+		y, _ := other.GetCpuUsagePercentage()
+		return compareFloat(x, y)
+	case ByMemoryUsage:
+		return compareValue(r.usage, other.usage, corev1.ResourceMemory)
+	case ByMemoryPercentage:
+		x, _ := r.GetMemoryUsagePercentage()
+		y, _ := other.GetMemoryUsagePercentage()
+		return compareFloat(x, y)
+	case ByNetworkRx:
+		x, _, _ := r.GetRxRate()
+		y, _, _ := other.GetRxRate()
+		return compareFloat(x, y)
+	case ByNetworkTx:
+		x, _, _ := r.GetTxRate()
+		y, _, _ := other.GetTxRate()
+		return compareFloat(x, y)
+	case ByFsPercentage:
+		x, _, _ := r.GetFsPercentage()
+		y, _, _ := other.GetFsPercentage()
+		return compareFloat(x, y)
+	default:
+		return compareString(r.nodeName, other.nodeName)
+	}
+}
+
+// header: "NODE", "STATUS", "PODS", "CPU(C)", "CPU(A)", "CPU(U)", "%CPU", "Memory(C)", "Memory(A)", "Memory(U)", "%Memory",
 func (r *NodeResource) toRow() []string {
 	return []string{
 		r.nodeName,
+		r.GetStatus(),
+		r.podsString(),
+		GetResourceValueString(r.capacity, corev1.ResourceCPU),
 		GetResourceValueString(r.allocatable, corev1.ResourceCPU),
 		GetResourceValueString(r.usage, corev1.ResourceCPU),
-		GetResourcePercentageString(*r.usage.Cpu(), *r.allocatable.Cpu()),
+		usagePercentageString(r.usage, r.allocatable, corev1.ResourceCPU),
+		GetResourceValueString(r.capacity, corev1.ResourceMemory),
 		GetResourceValueString(r.allocatable, corev1.ResourceMemory),
 		GetResourceValueString(r.usage, corev1.ResourceMemory),
-		GetResourcePercentageString(*r.usage.Memory(), *r.allocatable.Memory()),
+		usagePercentageString(r.usage, r.allocatable, corev1.ResourceMemory),
+	}
+}
+
+// header: "NET(RX)", "NET(TX)", "%FS", "%Inodes"
+func (r *NodeResource) toStatsRow() []string {
+	_, rx, _ := r.GetRxRate()
+	_, tx, _ := r.GetTxRate()
+	_, fs, _ := r.GetFsPercentage()
+	_, inodes, _ := r.GetInodesPercentage()
+	return []string{rx, tx, fs, inodes}
+}
+
+// usagePercentageString leaves the percentage unset for the nodes without metrics.
+func usagePercentageString(usage, allocatable corev1.ResourceList, name corev1.ResourceName) string {
+	val, ok := usage[name]
+	if !ok {
+		return "-"
 	}
+	return GetResourcePercentageString(val, allocatable[name])
 }
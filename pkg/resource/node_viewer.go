@@ -3,6 +3,7 @@ package resource
 import (
 	"image"
 	"sort"
+	"time"
 
 	. "github.com/ynqa/ktop/pkg/util"
 )
@@ -10,38 +11,61 @@ import (
 var (
 	nodeTitle  = "⎈ Node ⎈"
 	nodeHeader = []string{
-		"NODE",
-		"CPU(A)", "CPU(U)", "%CPU",
-		"Memory(A)", "Memory(U)", "%Memory",
+		"NODE", "STATUS", "PODS",
+		"CPU(C)", "CPU(A)", "CPU(U)", "%CPU",
+		"Memory(C)", "Memory(A)", "Memory(U)", "%Memory",
 	}
 	nodeWidthFn = func(rect image.Rectangle, maxLen int) []int {
-		nameWidth := IntMax(50, IntMin(rect.Dx()-20, maxLen+indentSize))
-		return []int{nameWidth, 10, 10, 10, 10, 10, 10}
+		nameWidth := IntMax(50, IntMin(rect.Dx()-110, maxLen+indentSize))
+		return []int{nameWidth, 26, 10, 10, 10, 10, 10, 10, 10, 10, 10}
 	}
+	nodeSortTypes = []SortType{
+		ByName,
+		ByCPUUsage, ByCPUPercentage,
+		ByMemoryUsage, ByMemoryPercentage,
+	}
+	nodeSortColumns = []int{0, 5, 6, 9, 10}
+	// columns of the percentages of cpu and memory usages
+	nodeUsageColumns = []int{6, 10}
 )
 
-func AsNodeTableViewer(resources []*NodeResource, sortType SortType) ResourceTableViewer {
-	switch sortType {
-	case ByName:
-		return sortByNameForNode(resources)
-	default:
-		return sortByNameForNode(resources)
+func AsNodeTableViewer(resources []*NodeResource, sortType SortType, order SortOrder) ResourceTableViewer {
+	return &nodeTableViewer{
+		resources: resources,
+		sortType:  sortType,
+		order:     order,
+		withStats: hasStats(len(resources), func(i int) bool { return resources[i].hasStats() }),
 	}
 }
 
-type sortByNameForNode []*NodeResource
+type nodeTableViewer struct {
+	resources []*NodeResource
+	sortType  SortType
+	order     SortOrder
+	// whether the stats of the kubelets are shown as columns
+	withStats bool
+}
 
-func (s sortByNameForNode) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
-	rows := make([][]string, len(s))
+func (v *nodeTableViewer) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
+	rows := make([][]string, len(v.resources))
 	var maxLen int
-	for i, v := range s {
-		rows[i] = v.toRow()
+	for i, r := range v.resources {
+		rows[i] = r.toRow()
+		if v.withStats {
+			rows[i] = append(rows[i], r.toStatsRow()...)
+		}
 		maxLen = IntMax(maxLen, len(rows[i][0]))
 	}
+	if v.withStats {
+		rect = statsRect(rect, nodeStatsHeader)
+	}
 	title, header, widths :=
 		nodeTitle, nodeHeader, nodeWidthFn(rect, maxLen)
+	if v.withStats {
+		header, widths = appendStatsColumns(header, widths, nodeStatsHeader)
+	}
 
-	if len(s) == 0 {
+	if len(v.resources) == 0 {
 		header = emptyHeader
 		widths = emptyWidthFn(rect)
 		rows = emptyRows
@@ -49,19 +73,48 @@ func (s sortByNameForNode) GetTableShape(rect image.Rectangle) (string, []string
 	return title, header, widths, rows
 }
 
-func (s sortByNameForNode) GetRows() [][]string {
-	if len(s) == 0 {
-		return emptyRows
+func (v *nodeTableViewer) GetRecords(timestamp time.Time) ([]string, []Record) {
+	records := make([]Record, len(v.resources))
+	for i, r := range v.resources {
+		records[i] = r.toRecord(timestamp)
 	}
-	rows := make([][]string, len(s))
-	for i, v := range s {
-		rows[i] = v.toRow()
+	return nodeRecordHeader, records
+}
+
+func (v *nodeTableViewer) GetSortColumn() int {
+	if len(v.resources) == 0 {
+		return -1
 	}
-	return rows
+	return statsSortColumnOf(v.sortType, v.withStats, nodeSortTypes, nodeSortColumns, nodeStatsSortTypes, nodeStatsSortColumns)
 }
 
-func (s sortByNameForNode) SortRows() {
-	sort.Slice(s, func(i, j int) bool {
-		return s[i].nodeName < s[j].nodeName
+func (v *nodeTableViewer) GetRowStates() []RowState {
+	states := make([]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = r.rowState()
+	}
+	return states
+}
+
+func (v *nodeTableViewer) GetCellStates(thresholds Thresholds) [][]RowState {
+	states := make([][]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = thresholds.cellStates(v.columns(), r.usage, r.allocatable, nodeUsageColumns)
+	}
+	return states
+}
+
+func (v *nodeTableViewer) SortRows() {
+	sort.SliceStable(v.resources, func(i, j int) bool {
+		a, b := v.resources[i], v.resources[j]
+		return less(v.order, a.compare(b, v.sortType), a.compare(b, ByName))
 	})
 }
+
+// columns returns the number of the columns including the stats.
+func (v *nodeTableViewer) columns() int {
+	if v.withStats {
+		return len(nodeHeader) + len(nodeStatsHeader)
+	}
+	return len(nodeHeader)
+}
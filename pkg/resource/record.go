@@ -0,0 +1,389 @@
+package resource
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+// Record is a machine-readable form of a table row.
+type Record interface {
+	CSVRow() []string
+}
+
+var (
+	containerRecordHeader = []string{
+		"timestamp", "namespace", "pod", "container", "node",
+		"status", "ready", "restarts", "lastTerminationReason",
+		"cpuUsageMillicores", "cpuLimitMillicores", "cpuRequestMillicores", "cpuLimitPercentage",
+		"memoryUsageBytes", "memoryLimitBytes", "memoryRequestBytes", "memoryLimitPercentage",
+	}
+	podRecordHeader = []string{
+		"timestamp", "namespace", "pod", "node",
+		"status", "readyContainers", "containers", "restarts", "lastTerminationReason", "createdAt",
+		"cpuUsageMillicores", "cpuLimitMillicores", "cpuRequestMillicores", "cpuLimitPercentage",
+		"memoryUsageBytes", "memoryLimitBytes", "memoryRequestBytes", "memoryLimitPercentage",
+	}
+	workloadRecordHeader = []string{
+		"timestamp", "namespace", "kind", "workload", "replicas",
+		"cpuUsageMillicores", "cpuAverageMillicores", "cpuLimitMillicores", "cpuRequestMillicores",
+		"memoryUsageBytes", "memoryAverageBytes", "memoryLimitBytes", "memoryRequestBytes",
+	}
+	namespaceRecordHeader = []string{
+		"timestamp", "namespace", "pods",
+		"cpuUsageMillicores", "cpuLimitMillicores", "cpuRequestMillicores",
+		"cpuLimitQuotaHardMillicores", "cpuLimitQuotaUsedMillicores",
+		"cpuRequestQuotaHardMillicores", "cpuRequestQuotaUsedMillicores",
+		"memoryUsageBytes", "memoryLimitBytes", "memoryRequestBytes",
+		"memoryLimitQuotaHardBytes", "memoryLimitQuotaUsedBytes",
+		"memoryRequestQuotaHardBytes", "memoryRequestQuotaUsedBytes",
+	}
+	nodeRecordHeader = []string{
+		"timestamp", "node", "status", "pods", "podsAllocatable",
+		"cpuCapacityMillicores", "cpuAllocatableMillicores", "cpuUsageMillicores", "cpuUsagePercentage",
+		"cpuRequestsMillicores", "cpuLimitsMillicores",
+		"memoryCapacityBytes", "memoryAllocatableBytes", "memoryUsageBytes", "memoryUsagePercentage",
+		"memoryRequestsBytes", "memoryLimitsBytes",
+	}
+)
+
+type ContainerRecord struct {
+	Timestamp             time.Time `json:"timestamp"`
+	Namespace             string    `json:"namespace"`
+	Pod                   string    `json:"pod"`
+	Container             string    `json:"container"`
+	Node                  string    `json:"node"`
+	Status                string    `json:"status"`
+	Ready                 bool      `json:"ready"`
+	Restarts              int64     `json:"restarts"`
+	LastTerminationReason string    `json:"lastTerminationReason"`
+	CPUUsageMillicores    *int64    `json:"cpuUsageMillicores"`
+	CPULimitMillicores    *int64    `json:"cpuLimitMillicores"`
+	CPURequestMillicores  *int64    `json:"cpuRequestMillicores"`
+	CPULimitPercentage    *float64  `json:"cpuLimitPercentage"`
+	MemoryUsageBytes      *int64    `json:"memoryUsageBytes"`
+	MemoryLimitBytes      *int64    `json:"memoryLimitBytes"`
+	MemoryRequestBytes    *int64    `json:"memoryRequestBytes"`
+	MemoryLimitPercentage *float64  `json:"memoryLimitPercentage"`
+}
+
+func (r *Resource) toRecord(timestamp time.Time) *ContainerRecord {
+	cpuUsage := optionalValue(r.usage, corev1.ResourceCPU)
+	memUsage := optionalValue(r.usage, corev1.ResourceMemory)
+	cpuLimit := optionalValue(r.limits, corev1.ResourceCPU)
+	memLimit := optionalValue(r.limits, corev1.ResourceMemory)
+	return &ContainerRecord{
+		Timestamp:             timestamp,
+		Namespace:             r.namespace,
+		Pod:                   r.podName,
+		Container:             r.containerName,
+		Node:                  r.nodeName,
+		Status:                r.status.reason,
+		Ready:                 r.status.ready == r.status.total,
+		Restarts:              int64(r.status.restarts),
+		LastTerminationReason: r.status.lastReason,
+		CPUUsageMillicores:    cpuUsage,
+		CPULimitMillicores:    cpuLimit,
+		CPURequestMillicores:  optionalValue(r.requests, corev1.ResourceCPU),
+		CPULimitPercentage:    optionalPercentage(cpuUsage, cpuLimit),
+		MemoryUsageBytes:      memUsage,
+		MemoryLimitBytes:      memLimit,
+		MemoryRequestBytes:    optionalValue(r.requests, corev1.ResourceMemory),
+		MemoryLimitPercentage: optionalPercentage(memUsage, memLimit),
+	}
+}
+
+func (r *ContainerRecord) CSVRow() []string {
+	return []string{
+		formatTimestamp(r.Timestamp), r.Namespace, r.Pod, r.Container, r.Node,
+		r.Status, strconv.FormatBool(r.Ready), formatInt(&r.Restarts), r.LastTerminationReason,
+		formatInt(r.CPUUsageMillicores), formatInt(r.CPULimitMillicores),
+		formatInt(r.CPURequestMillicores), formatFloat(r.CPULimitPercentage),
+		formatInt(r.MemoryUsageBytes), formatInt(r.MemoryLimitBytes),
+		formatInt(r.MemoryRequestBytes), formatFloat(r.MemoryLimitPercentage),
+	}
+}
+
+type PodRecord struct {
+	Timestamp             time.Time `json:"timestamp"`
+	Namespace             string    `json:"namespace"`
+	Pod                   string    `json:"pod"`
+	Node                  string    `json:"node"`
+	Status                string    `json:"status"`
+	ReadyContainers       int64     `json:"readyContainers"`
+	Containers            int64     `json:"containers"`
+	Restarts              int64     `json:"restarts"`
+	LastTerminationReason string    `json:"lastTerminationReason"`
+	CreatedAt             time.Time `json:"createdAt"`
+	CPUUsageMillicores    *int64    `json:"cpuUsageMillicores"`
+	CPULimitMillicores    *int64    `json:"cpuLimitMillicores"`
+	CPURequestMillicores  *int64    `json:"cpuRequestMillicores"`
+	CPULimitPercentage    *float64  `json:"cpuLimitPercentage"`
+	MemoryUsageBytes      *int64    `json:"memoryUsageBytes"`
+	MemoryLimitBytes      *int64    `json:"memoryLimitBytes"`
+	MemoryRequestBytes    *int64    `json:"memoryRequestBytes"`
+	MemoryLimitPercentage *float64  `json:"memoryLimitPercentage"`
+}
+
+func (s *SummarizedResource) toRecord(timestamp time.Time) *PodRecord {
+	cpuUsage := optionalValue(s.usage, corev1.ResourceCPU)
+	memUsage := optionalValue(s.usage, corev1.ResourceMemory)
+	// the limits are left unset if any container does not define them
+	cpuLimit := optionalValue(s.limits, corev1.ResourceCPU)
+	memLimit := optionalValue(s.limits, corev1.ResourceMemory)
+	return &PodRecord{
+		Timestamp:             timestamp,
+		Namespace:             s.namespace,
+		Pod:                   s.podName,
+		Node:                  s.nodeName,
+		Status:                s.status.reason,
+		ReadyContainers:       int64(s.status.ready),
+		Containers:            int64(s.status.total),
+		Restarts:              int64(s.status.restarts),
+		LastTerminationReason: s.status.lastReason,
+		CreatedAt:             s.status.createdAt,
+		CPUUsageMillicores:    cpuUsage,
+		CPULimitMillicores:    cpuLimit,
+		CPURequestMillicores:  optionalValue(s.requests, corev1.ResourceCPU),
+		CPULimitPercentage:    optionalPercentage(cpuUsage, cpuLimit),
+		MemoryUsageBytes:      memUsage,
+		MemoryLimitBytes:      memLimit,
+		MemoryRequestBytes:    optionalValue(s.requests, corev1.ResourceMemory),
+		MemoryLimitPercentage: optionalPercentage(memUsage, memLimit),
+	}
+}
+
+func (r *PodRecord) CSVRow() []string {
+	return []string{
+		formatTimestamp(r.Timestamp), r.Namespace, r.Pod, r.Node,
+		r.Status, formatInt(&r.ReadyContainers), formatInt(&r.Containers),
+		formatInt(&r.Restarts), r.LastTerminationReason, formatTimestamp(r.CreatedAt),
+		formatInt(r.CPUUsageMillicores), formatInt(r.CPULimitMillicores),
+		formatInt(r.CPURequestMillicores), formatFloat(r.CPULimitPercentage),
+		formatInt(r.MemoryUsageBytes), formatInt(r.MemoryLimitBytes),
+		formatInt(r.MemoryRequestBytes), formatFloat(r.MemoryLimitPercentage),
+	}
+}
+
+type WorkloadRecord struct {
+	Timestamp            time.Time `json:"timestamp"`
+	Namespace            string    `json:"namespace"`
+	Kind                 string    `json:"kind"`
+	Workload             string    `json:"workload"`
+	Replicas             int64     `json:"replicas"`
+	CPUUsageMillicores   int64     `json:"cpuUsageMillicores"`
+	CPUAverageMillicores int64     `json:"cpuAverageMillicores"`
+	CPULimitMillicores   *int64    `json:"cpuLimitMillicores"`
+	CPURequestMillicores *int64    `json:"cpuRequestMillicores"`
+	MemoryUsageBytes     int64     `json:"memoryUsageBytes"`
+	MemoryAverageBytes   int64     `json:"memoryAverageBytes"`
+	MemoryLimitBytes     *int64    `json:"memoryLimitBytes"`
+	MemoryRequestBytes   *int64    `json:"memoryRequestBytes"`
+}
+
+func (w *WorkloadResource) toRecord(timestamp time.Time) *WorkloadRecord {
+	cpuUsage, _ := GetRawResourceValue(w.usage, corev1.ResourceCPU)
+	cpuAverage, _ := GetRawResourceValue(w.average(corev1.ResourceCPU), corev1.ResourceCPU)
+	memUsage, _ := GetRawResourceValue(w.usage, corev1.ResourceMemory)
+	memAverage, _ := GetRawResourceValue(w.average(corev1.ResourceMemory), corev1.ResourceMemory)
+	return &WorkloadRecord{
+		Timestamp:            timestamp,
+		Namespace:            w.namespace,
+		Kind:                 w.kind,
+		Workload:             w.name,
+		Replicas:             int64(w.replicas),
+		CPUUsageMillicores:   cpuUsage,
+		CPUAverageMillicores: cpuAverage,
+		CPULimitMillicores:   optionalValue(w.limits, corev1.ResourceCPU),
+		CPURequestMillicores: optionalValue(w.requests, corev1.ResourceCPU),
+		MemoryUsageBytes:     memUsage,
+		MemoryAverageBytes:   memAverage,
+		MemoryLimitBytes:     optionalValue(w.limits, corev1.ResourceMemory),
+		MemoryRequestBytes:   optionalValue(w.requests, corev1.ResourceMemory),
+	}
+}
+
+func (r *WorkloadRecord) CSVRow() []string {
+	return []string{
+		formatTimestamp(r.Timestamp), r.Namespace, r.Kind, r.Workload, formatInt(&r.Replicas),
+		formatInt(&r.CPUUsageMillicores), formatInt(&r.CPUAverageMillicores),
+		formatInt(r.CPULimitMillicores), formatInt(r.CPURequestMillicores),
+		formatInt(&r.MemoryUsageBytes), formatInt(&r.MemoryAverageBytes),
+		formatInt(r.MemoryLimitBytes), formatInt(r.MemoryRequestBytes),
+	}
+}
+
+type NamespaceRecord struct {
+	Timestamp                     time.Time `json:"timestamp"`
+	Namespace                     string    `json:"namespace"`
+	Pods                          int64     `json:"pods"`
+	CPUUsageMillicores            int64     `json:"cpuUsageMillicores"`
+	CPULimitMillicores            int64     `json:"cpuLimitMillicores"`
+	CPURequestMillicores          int64     `json:"cpuRequestMillicores"`
+	CPULimitQuotaHardMillicores   *int64    `json:"cpuLimitQuotaHardMillicores"`
+	CPULimitQuotaUsedMillicores   *int64    `json:"cpuLimitQuotaUsedMillicores"`
+	CPURequestQuotaHardMillicores *int64    `json:"cpuRequestQuotaHardMillicores"`
+	CPURequestQuotaUsedMillicores *int64    `json:"cpuRequestQuotaUsedMillicores"`
+	MemoryUsageBytes              int64     `json:"memoryUsageBytes"`
+	MemoryLimitBytes              int64     `json:"memoryLimitBytes"`
+	MemoryRequestBytes            int64     `json:"memoryRequestBytes"`
+	MemoryLimitQuotaHardBytes     *int64    `json:"memoryLimitQuotaHardBytes"`
+	MemoryLimitQuotaUsedBytes     *int64    `json:"memoryLimitQuotaUsedBytes"`
+	MemoryRequestQuotaHardBytes   *int64    `json:"memoryRequestQuotaHardBytes"`
+	MemoryRequestQuotaUsedBytes   *int64    `json:"memoryRequestQuotaUsedBytes"`
+}
+
+func (n *NamespaceResource) toRecord(timestamp time.Time) *NamespaceRecord {
+	cpuUsage, _ := GetRawResourceValue(n.usage, corev1.ResourceCPU)
+	cpuLimit, _ := GetRawResourceValue(n.limits, corev1.ResourceCPU)
+	cpuRequest, _ := GetRawResourceValue(n.requests, corev1.ResourceCPU)
+	memUsage, _ := GetRawResourceValue(n.usage, corev1.ResourceMemory)
+	memLimit, _ := GetRawResourceValue(n.limits, corev1.ResourceMemory)
+	memRequest, _ := GetRawResourceValue(n.requests, corev1.ResourceMemory)
+	record := &NamespaceRecord{
+		Timestamp:            timestamp,
+		Namespace:            n.name,
+		Pods:                 int64(n.pods),
+		CPUUsageMillicores:   cpuUsage,
+		CPULimitMillicores:   cpuLimit,
+		CPURequestMillicores: cpuRequest,
+		MemoryUsageBytes:     memUsage,
+		MemoryLimitBytes:     memLimit,
+		MemoryRequestBytes:   memRequest,
+	}
+	record.CPULimitQuotaHardMillicores, record.CPULimitQuotaUsedMillicores =
+		optionalQuota(n.limitsQuota, corev1.ResourceCPU)
+	record.CPURequestQuotaHardMillicores, record.CPURequestQuotaUsedMillicores =
+		optionalQuota(n.requestsQuota, corev1.ResourceCPU)
+	record.MemoryLimitQuotaHardBytes, record.MemoryLimitQuotaUsedBytes =
+		optionalQuota(n.limitsQuota, corev1.ResourceMemory)
+	record.MemoryRequestQuotaHardBytes, record.MemoryRequestQuotaUsedBytes =
+		optionalQuota(n.requestsQuota, corev1.ResourceMemory)
+	return record
+}
+
+func (r *NamespaceRecord) CSVRow() []string {
+	return []string{
+		formatTimestamp(r.Timestamp), r.Namespace, formatInt(&r.Pods),
+		formatInt(&r.CPUUsageMillicores), formatInt(&r.CPULimitMillicores), formatInt(&r.CPURequestMillicores),
+		formatInt(r.CPULimitQuotaHardMillicores), formatInt(r.CPULimitQuotaUsedMillicores),
+		formatInt(r.CPURequestQuotaHardMillicores), formatInt(r.CPURequestQuotaUsedMillicores),
+		formatInt(&r.MemoryUsageBytes), formatInt(&r.MemoryLimitBytes), formatInt(&r.MemoryRequestBytes),
+		formatInt(r.MemoryLimitQuotaHardBytes), formatInt(r.MemoryLimitQuotaUsedBytes),
+		formatInt(r.MemoryRequestQuotaHardBytes), formatInt(r.MemoryRequestQuotaUsedBytes),
+	}
+}
+
+type NodeRecord struct {
+	Timestamp                time.Time `json:"timestamp"`
+	Node                     string    `json:"node"`
+	Status                   string    `json:"status"`
+	Pods                     *int64    `json:"pods"`
+	PodsAllocatable          int64     `json:"podsAllocatable"`
+	CPUCapacityMillicores    int64     `json:"cpuCapacityMillicores"`
+	CPUAllocatableMillicores int64     `json:"cpuAllocatableMillicores"`
+	CPUUsageMillicores       *int64    `json:"cpuUsageMillicores"`
+	CPUUsagePercentage       *float64  `json:"cpuUsagePercentage"`
+	CPURequestsMillicores    *int64    `json:"cpuRequestsMillicores"`
+	CPULimitsMillicores      *int64    `json:"cpuLimitsMillicores"`
+	MemoryCapacityBytes      int64     `json:"memoryCapacityBytes"`
+	MemoryAllocatableBytes   int64     `json:"memoryAllocatableBytes"`
+	MemoryUsageBytes         *int64    `json:"memoryUsageBytes"`
+	MemoryUsagePercentage    *float64  `json:"memoryUsagePercentage"`
+	MemoryRequestsBytes      *int64    `json:"memoryRequestsBytes"`
+	MemoryLimitsBytes        *int64    `json:"memoryLimitsBytes"`
+}
+
+func (r *NodeResource) toRecord(timestamp time.Time) *NodeRecord {
+	cpuCapacity, _ := GetRawResourceValue(r.capacity, corev1.ResourceCPU)
+	cpuAllocatable, _ := GetRawResourceValue(r.allocatable, corev1.ResourceCPU)
+	cpuUsage := optionalValue(r.usage, corev1.ResourceCPU)
+	memCapacity, _ := GetRawResourceValue(r.capacity, corev1.ResourceMemory)
+	memAllocatable, _ := GetRawResourceValue(r.allocatable, corev1.ResourceMemory)
+	memUsage := optionalValue(r.usage, corev1.ResourceMemory)
+	// the pods are only listed for the node whose detail is opened
+	var pods *int64
+	if r.podsListed {
+		n := int64(r.pods)
+		pods = &n
+	}
+	podsAllocatable := r.allocatable[corev1.ResourcePods]
+	return &NodeRecord{
+		Timestamp:                timestamp,
+		Node:                     r.nodeName,
+		Status:                   r.GetStatus(),
+		Pods:                     pods,
+		PodsAllocatable:          podsAllocatable.Value(),
+		CPUCapacityMillicores:    cpuCapacity,
+		CPUAllocatableMillicores: cpuAllocatable,
+		CPUUsageMillicores:       cpuUsage,
+		CPUUsagePercentage:       optionalPercentage(cpuUsage, &cpuAllocatable),
+		CPURequestsMillicores:    optionalValue(r.allocatedRequests, corev1.ResourceCPU),
+		CPULimitsMillicores:      optionalValue(r.allocatedLimits, corev1.ResourceCPU),
+		MemoryCapacityBytes:      memCapacity,
+		MemoryAllocatableBytes:   memAllocatable,
+		MemoryUsageBytes:         memUsage,
+		MemoryUsagePercentage:    optionalPercentage(memUsage, &memAllocatable),
+		MemoryRequestsBytes:      optionalValue(r.allocatedRequests, corev1.ResourceMemory),
+		MemoryLimitsBytes:        optionalValue(r.allocatedLimits, corev1.ResourceMemory),
+	}
+}
+
+func (r *NodeRecord) CSVRow() []string {
+	return []string{
+		formatTimestamp(r.Timestamp), r.Node, r.Status, formatInt(r.Pods), formatInt(&r.PodsAllocatable),
+		formatInt(&r.CPUCapacityMillicores), formatInt(&r.CPUAllocatableMillicores),
+		formatInt(r.CPUUsageMillicores), formatFloat(r.CPUUsagePercentage),
+		formatInt(r.CPURequestsMillicores), formatInt(r.CPULimitsMillicores),
+		formatInt(&r.MemoryCapacityBytes), formatInt(&r.MemoryAllocatableBytes),
+		formatInt(r.MemoryUsageBytes), formatFloat(r.MemoryUsagePercentage),
+		formatInt(r.MemoryRequestsBytes), formatInt(r.MemoryLimitsBytes),
+	}
+}
+
+func optionalValue(lst corev1.ResourceList, typ corev1.ResourceName) *int64 {
+	val, ok := GetRawResourceValue(lst, typ)
+	if !ok {
+		return nil
+	}
+	return &val
+}
+
+func optionalQuota(quotas map[corev1.ResourceName]quotaStatus, name corev1.ResourceName) (*int64, *int64) {
+	status, ok := quotas[name]
+	if !ok {
+		return nil, nil
+	}
+	hard := optionalValue(corev1.ResourceList{name: status.hard}, name)
+	used := optionalValue(corev1.ResourceList{name: status.used}, name)
+	return hard, used
+}
+
+func optionalPercentage(usage, limit *int64) *float64 {
+	if usage == nil || limit == nil || *limit == 0 {
+		return nil
+	}
+	percentage := float64(*usage) / float64(*limit) * 100
+	return &percentage
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func formatInt(val *int64) string {
+	if val == nil {
+		return ""
+	}
+	return strconv.FormatInt(*val, 10)
+}
+
+func formatFloat(val *float64) string {
+	if val == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*val, 'f', 2, 64)
+}
@@ -1,6 +1,8 @@
 package resource
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/metrics/pkg/apis/metrics"
 
@@ -8,22 +10,30 @@ import (
 )
 
 type Resource struct {
+	namespace     string
 	nodeName      string
 	podName       string
 	containerName string
 	usage         corev1.ResourceList
 	limits        corev1.ResourceList
 	requests      corev1.ResourceList
+	status        status
+	// stats of the kubelet, where the network is shared by the containers of the pod
+	network *NetworkStats
+	rootfs  *FsStats
+	logs    *FsStats
 }
 
 func NewResource(p corev1.Pod, c corev1.Container, cm metrics.ContainerMetrics) *Resource {
 	return &Resource{
+		namespace:     p.Namespace,
 		nodeName:      p.Spec.NodeName,
 		podName:       p.Name,
 		containerName: c.Name,
 		usage:         cm.Usage,
 		limits:        c.Resources.Limits,
 		requests:      c.Resources.Requests,
+		status:        newContainerStatus(p, c.Name),
 	}
 }
 
@@ -31,6 +41,14 @@ func (r *Resource) GetNodeName() string {
 	return r.nodeName
 }
 
+func (r *Resource) GetNamespace() string {
+	return r.namespace
+}
+
+func (r *Resource) GetPodName() string {
+	return r.podName
+}
+
 func (r *Resource) GetContainerName() string {
 	return r.containerName
 }
@@ -41,6 +59,12 @@ func (r *Resource) GetCpuLimits() (float64, string, bool) {
 	return GetResourceValue(r.limits, corev1.ResourceCPU), str, ok
 }
 
+// HasUsage returns whether the metrics of the container are reported.
+func (r *Resource) HasUsage() bool {
+	_, ok := r.usage[corev1.ResourceCPU]
+	return ok
+}
+
 func (r *Resource) GetCpuUsage() (float64, string) {
 	return GetResourceValue(r.usage, corev1.ResourceCPU),
 		GetResourceValueString(r.usage, corev1.ResourceCPU)
@@ -57,16 +81,125 @@ func (r *Resource) GetMemoryUsage() (float64, string) {
 		GetResourceValueString(r.usage, corev1.ResourceMemory)
 }
 
-// header: "POD", "CONTAINER", "CPU(U)", "CPU(L)", "CPU(R)", "Mem(U)", "Mem(L)", "Mem(R)"
+func (r *Resource) GetCpuRequests() (float64, string, bool) {
+	return getOptionalValue(r.requests, corev1.ResourceCPU)
+}
+
+func (r *Resource) GetMemoryRequests() (float64, string, bool) {
+	return getOptionalValue(r.requests, corev1.ResourceMemory)
+}
+
+// SetStats sets the stats reported by the kubelet.
+func (r *Resource) SetStats(network *NetworkStats, rootfs, logs *FsStats) {
+	r.network = network
+	r.rootfs = rootfs
+	r.logs = logs
+}
+
+func (r *Resource) hasStats() bool {
+	return r.rootfs != nil || r.logs != nil
+}
+
+func (r *Resource) GetRxRate() (float64, string, bool) {
+	return r.network.rxRate()
+}
+
+func (r *Resource) GetTxRate() (float64, string, bool) {
+	return r.network.txRate()
+}
+
+// GetFsUsage returns the usage of the writable layer and the logs,
+// which are limited by the ephemeral storage.
+func (r *Resource) GetFsUsage() (float64, string, bool) {
+	if !r.hasStats() {
+		return 0, "-", false
+	}
+	fs := &FsStats{}
+	for _, s := range []*FsStats{r.rootfs, r.logs} {
+		if s != nil {
+			fs.UsedBytes += s.UsedBytes
+		}
+	}
+	return fs.used()
+}
+
+func (r *Resource) GetLogsUsage() (float64, string, bool) {
+	return r.logs.used()
+}
+
+// GetFsLimits returns the limit of the ephemeral storage.
+func (r *Resource) GetFsLimits() (float64, string, bool) {
+	return ephemeralStorageLimit(r.limits)
+}
+
+// GetInodesUsage returns the inodes used by the writable layer.
+func (r *Resource) GetInodesUsage() (float64, string, bool) {
+	return r.rootfs.inodesUsed()
+}
+
+func (r *Resource) compare(other *Resource, sortType SortType) int {
+	switch sortType {
+	case ByNodeName:
+		return compareString(r.nodeName, other.nodeName)
+	case ByRestarts:
+		return compareFloat(float64(r.status.restarts), float64(other.status.restarts))
+	case ByCPUUsage:
+		return compareValue(r.usage, other.usage, corev1.ResourceCPU)
+	case ByCPULimit:
+		return compareValue(r.limits, other.limits, corev1.ResourceCPU)
+	case ByCPURequest:
+		return compareValue(r.requests, other.requests, corev1.ResourceCPU)
+	case ByMemoryUsage:
+		return compareValue(r.usage, other.usage, corev1.ResourceMemory)
+	case ByMemoryLimit:
+		return compareValue(r.limits, other.limits, corev1.ResourceMemory)
+	case ByMemoryRequest:
+		return compareValue(r.requests, other.requests, corev1.ResourceMemory)
+	case ByCPUPercentage:
+		return comparePercentage(r.usage, r.limits, other.usage, other.limits, corev1.ResourceCPU)
+	case ByMemoryPercentage:
+		return comparePercentage(r.usage, r.limits, other.usage, other.limits, corev1.ResourceMemory)
+	case ByFsUsage:
+		x, _, _ := r.GetFsUsage()
+		y, _, _ := other.GetFsUsage()
+		return compareFloat(x, y)
+	default:
+		if cmp := compareString(r.namespace, other.namespace); cmp != 0 {
+			return cmp
+		}
+		if cmp := compareString(r.podName, other.podName); cmp != 0 {
+			return cmp
+		}
+		return compareString(r.containerName, other.containerName)
+	}
+}
+
+// header: "NAMESPACE", "POD", "CONTAINER", "STATUS", "READY", "RESTARTS", "LAST REASON", "AGE", "CPU(U)", "CPU(L)", "CPU(R)", "%CPU", "Mem(U)", "Mem(L)", "Mem(R)", "%Mem"
 func (r *Resource) toRow() []string {
 	return []string{
+		r.namespace,
 		r.podName,
 		r.containerName,
+		r.status.reason,
+		r.status.readyString(),
+		r.status.restartsString(),
+		r.status.lastReasonString(),
+		r.status.ageString(time.Now()),
 		GetResourceValueString(r.usage, corev1.ResourceCPU),
 		GetResourceValueString(r.limits, corev1.ResourceCPU),
 		GetResourceValueString(r.requests, corev1.ResourceCPU),
+		limitPercentageString(r.usage, r.limits, corev1.ResourceCPU),
 		GetResourceValueString(r.usage, corev1.ResourceMemory),
 		GetResourceValueString(r.limits, corev1.ResourceMemory),
 		GetResourceValueString(r.requests, corev1.ResourceMemory),
+		limitPercentageString(r.usage, r.limits, corev1.ResourceMemory),
 	}
 }
+
+// header: "FS(U)", "Logs(U)", "FS(L)"
+func (r *Resource) toStatsRow() []string {
+	_, fs, _ := r.GetFsUsage()
+	_, logs, _ := r.GetLogsUsage()
+	_, limit, _ := r.GetFsLimits()
+	return []string{fs, logs, limit}
+}
@@ -4,18 +4,23 @@ import (
 	"container/ring"
 	"image"
 	"sort"
+	"time"
 
 	. "github.com/ynqa/ktop/pkg/util"
 )
 
-type SortType int
-
-const (
-	ByName SortType = iota
-)
-
 type ResourceTableViewer interface {
 	GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string)
+	GetRecords(timestamp time.Time) ([]string, []Record)
+	// GetSortColumn returns the index of the sorted column in the header,
+	// or -1 if the sorted key is not shown as a column.
+	GetSortColumn() int
+	// GetRowStates returns the states of the rows to highlight,
+	// or nil if all rows are normal.
+	GetRowStates() []RowState
+	// GetCellStates returns the states of the cells crossing the thresholds,
+	// or nil if the table has no usages to compare.
+	GetCellStates(thresholds Thresholds) [][]RowState
 	SortRows()
 }
 
@@ -23,19 +28,35 @@ var (
 	SummarizedType = "Summarized"
 	AllType        = "All"
 	NodeType       = "Node"
+	WorkloadType   = "Workload"
+	NamespaceType  = "Namespace"
 
 	allTitle  = "⎈ Pod/Container ⎈"
 	allHeader = []string{
-		"POD", "CONTAINER",
-		"CPU(U)", "CPU(L)", "CPU(R)",
-		"Memory(U)", "Memory(L)", "Memory(R)",
+		"NAMESPACE", "POD", "CONTAINER",
+		"STATUS", "READY", "RESTARTS", "LAST REASON", "AGE",
+		"CPU(U)", "CPU(L)", "CPU(R)", "%CPU",
+		"Memory(U)", "Memory(L)", "Memory(R)", "%Memory",
+	}
+	indentSize        = 4
+	namespaceMinWidth = 15
+	// widths of "STATUS", "READY", "RESTARTS", "LAST REASON", "AGE" of the pod tables
+	statusWidths = []int{20, 7, 10, 13, 7}
+	allWidthFn   = func(rect image.Rectangle, maxLen0, maxLen1, maxLen2 int) []int {
+		namespaceWidth := IntMax(namespaceMinWidth, IntMin(rect.Dx()-137, maxLen0+indentSize))
+		podWidth := IntMax(40, IntMin(rect.Dx()-137, maxLen1+indentSize))
+		containerWidth := IntMax(30, IntMin(rect.Dx()-137, maxLen2+indentSize))
+		widths := append([]int{namespaceWidth, podWidth, containerWidth}, statusWidths...)
+		return append(widths, 10, 10, 10, 10, 10, 10, 10, 10)
 	}
-	indentSize = 4
-	allWidthFn = func(rect image.Rectangle, maxLen0, maxLen1 int) []int {
-		podWidth := IntMax(40, IntMin(rect.Dx()-60, maxLen0+indentSize))
-		containerWidth := IntMax(30, IntMin(rect.Dx()-60, maxLen1+indentSize))
-		return []int{podWidth, containerWidth, 10, 10, 10, 10, 10, 10}
+	allSortTypes = []SortType{
+		ByName, ByNodeName, ByRestarts,
+		ByCPUUsage, ByCPULimit, ByCPURequest, ByCPUPercentage,
+		ByMemoryUsage, ByMemoryLimit, ByMemoryRequest, ByMemoryPercentage,
 	}
+	allSortColumns = []int{1, -1, 5, 8, 9, 10, 11, 12, 13, 14, 15}
+	// columns of the percentages of cpu and memory usages
+	allUsageColumns = []int{11, 15}
 
 	emptyHeader = []string{
 		"Message",
@@ -47,7 +68,7 @@ var (
 )
 
 func TableTypeCircle() *ring.Ring {
-	types := []string{SummarizedType, AllType, NodeType}
+	types := []string{SummarizedType, AllType, NodeType, WorkloadType, NamespaceType}
 	circle := ring.New(len(types))
 	for _, typ := range types {
 		circle.Value = typ
@@ -59,39 +80,59 @@ func TableTypeCircle() *ring.Ring {
 func ResetTableShapeFrom(typ string, rect image.Rectangle) (string, []string, []int) {
 	switch typ {
 	case SummarizedType:
-		return summarizedTitle, summarizedHeader, summarizedWidthFn(rect, 0)
+		return summarizedTitle, summarizedHeader, summarizedWidthFn(rect, 0, 0)
 	case AllType:
-		return allTitle, allHeader, allWidthFn(rect, 0, 0)
+		return allTitle, allHeader, allWidthFn(rect, 0, 0, 0)
 	case NodeType:
 		return nodeTitle, nodeHeader, nodeWidthFn(rect, 0)
+	case WorkloadType:
+		return workloadTitle, workloadHeader, workloadWidthFn(rect, 0, 0)
+	case NamespaceType:
+		return namespaceTitle, namespaceHeader, namespaceWidthFn(rect, 0)
 	default:
-		return summarizedTitle, summarizedHeader, summarizedWidthFn(rect, 0)
+		return summarizedTitle, summarizedHeader, summarizedWidthFn(rect, 0, 0)
 	}
 }
 
-func AsAllTableViewer(resources []*Resource, sortType SortType) ResourceTableViewer {
-	switch sortType {
-	case ByName:
-		return sortByName(resources)
-	default:
-		return sortByName(resources)
+func AsAllTableViewer(resources []*Resource, sortType SortType, order SortOrder) ResourceTableViewer {
+	return &allTableViewer{
+		resources: resources,
+		sortType:  sortType,
+		order:     order,
+		withStats: hasStats(len(resources), func(i int) bool { return resources[i].hasStats() }),
 	}
 }
 
-type sortByName []*Resource
+type allTableViewer struct {
+	resources []*Resource
+	sortType  SortType
+	order     SortOrder
+	// whether the stats of the kubelets are shown as columns
+	withStats bool
+}
 
-func (s sortByName) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
-	rows := make([][]string, len(s))
-	var maxLen0, maxLen1 int
-	for i, v := range s {
-		rows[i] = v.toRow()
+func (v *allTableViewer) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
+	rows := make([][]string, len(v.resources))
+	var maxLen0, maxLen1, maxLen2 int
+	for i, r := range v.resources {
+		rows[i] = r.toRow()
+		if v.withStats {
+			rows[i] = append(rows[i], r.toStatsRow()...)
+		}
 		maxLen0 = IntMax(maxLen0, len(rows[i][0]))
 		maxLen1 = IntMax(maxLen1, len(rows[i][1]))
+		maxLen2 = IntMax(maxLen2, len(rows[i][2]))
+	}
+	if v.withStats {
+		rect = statsRect(rect, allStatsHeader)
 	}
 	title, header, widths :=
-		allTitle, allHeader, allWidthFn(rect, maxLen0, maxLen1)
+		allTitle, allHeader, allWidthFn(rect, maxLen0, maxLen1, maxLen2)
+	if v.withStats {
+		header, widths = appendStatsColumns(header, widths, allStatsHeader)
+	}
 
-	if len(s) == 0 {
+	if len(v.resources) == 0 {
 		header = emptyHeader
 		widths = emptyWidthFn(rect)
 		rows = emptyRows
@@ -99,14 +140,49 @@ func (s sortByName) GetTableShape(rect image.Rectangle) (string, []string, []int
 	return title, header, widths, rows
 }
 
-func (s sortByName) SortRows() {
-	sort.Slice(s, func(i, j int) bool {
-		if s[i].podName < s[j].podName {
-			return true
-		}
-		if s[i].podName > s[j].podName {
-			return false
-		}
-		return s[i].containerName < s[j].containerName
+func (v *allTableViewer) GetRecords(timestamp time.Time) ([]string, []Record) {
+	records := make([]Record, len(v.resources))
+	for i, r := range v.resources {
+		records[i] = r.toRecord(timestamp)
+	}
+	return containerRecordHeader, records
+}
+
+func (v *allTableViewer) GetSortColumn() int {
+	if len(v.resources) == 0 {
+		return -1
+	}
+	return statsSortColumnOf(v.sortType, v.withStats, allSortTypes, allSortColumns, allStatsSortTypes, allStatsSortColumns)
+}
+
+func (v *allTableViewer) GetRowStates() []RowState {
+	now := time.Now()
+	states := make([]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = r.status.rowState(now)
+	}
+	return states
+}
+
+func (v *allTableViewer) GetCellStates(thresholds Thresholds) [][]RowState {
+	states := make([][]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = thresholds.cellStates(v.columns(), r.usage, r.limits, allUsageColumns)
+	}
+	return states
+}
+
+func (v *allTableViewer) SortRows() {
+	sort.SliceStable(v.resources, func(i, j int) bool {
+		a, b := v.resources[i], v.resources[j]
+		return less(v.order, a.compare(b, v.sortType), a.compare(b, ByName))
 	})
 }
+
+// columns returns the number of the columns including the stats.
+func (v *allTableViewer) columns() int {
+	if v.withStats {
+		return len(allHeader) + len(allStatsHeader)
+	}
+	return len(allHeader)
+}
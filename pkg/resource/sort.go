@@ -0,0 +1,167 @@
+package resource
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+type SortType int
+
+const (
+	ByName SortType = iota
+	ByNodeName
+	ByCPUUsage
+	ByCPULimit
+	ByCPURequest
+	ByCPUPercentage
+	ByMemoryUsage
+	ByMemoryLimit
+	ByMemoryRequest
+	ByMemoryPercentage
+	ByRestarts
+	ByNetworkRx
+	ByNetworkTx
+	ByFsUsage
+	ByFsPercentage
+)
+
+func (s SortType) String() string {
+	switch s {
+	case ByName:
+		return "NAME"
+	case ByNodeName:
+		return "NODE"
+	case ByCPUUsage:
+		return "CPU(U)"
+	case ByCPULimit:
+		return "CPU(L)"
+	case ByCPURequest:
+		return "CPU(R)"
+	case ByCPUPercentage:
+		return "%CPU"
+	case ByMemoryUsage:
+		return "Memory(U)"
+	case ByMemoryLimit:
+		return "Memory(L)"
+	case ByMemoryRequest:
+		return "Memory(R)"
+	case ByMemoryPercentage:
+		return "%Memory"
+	case ByRestarts:
+		return "RESTARTS"
+	case ByNetworkRx:
+		return "NET(RX)"
+	case ByNetworkTx:
+		return "NET(TX)"
+	case ByFsUsage:
+		return "FS(U)"
+	case ByFsPercentage:
+		return "%FS"
+	default:
+		return ""
+	}
+}
+
+// DefaultOrder returns the order which is used when the sort type is selected,
+// i.e. names in ascending and usages in descending.
+func (s SortType) DefaultOrder() SortOrder {
+	switch s {
+	case ByName, ByNodeName:
+		return Ascending
+	default:
+		return Descending
+	}
+}
+
+type SortOrder int
+
+const (
+	Ascending SortOrder = iota
+	Descending
+)
+
+func (o SortOrder) Reverse() SortOrder {
+	if o == Ascending {
+		return Descending
+	}
+	return Ascending
+}
+
+// SortTypesOf returns the sort types available for the table type in cycle order,
+// including the stats of the kubelets if withStats is set.
+func SortTypesOf(typ string, withStats bool) []SortType {
+	switch typ {
+	case SummarizedType:
+		return appendStatsSortTypes(summarizedSortTypes, summarizedStatsSortTypes, withStats)
+	case AllType:
+		return appendStatsSortTypes(allSortTypes, allStatsSortTypes, withStats)
+	case NodeType:
+		return appendStatsSortTypes(nodeSortTypes, nodeStatsSortTypes, withStats)
+	case WorkloadType:
+		return workloadSortTypes
+	case NamespaceType:
+		return namespaceSortTypes
+	default:
+		return []SortType{ByName}
+	}
+}
+
+func appendStatsSortTypes(sortTypes, statsSortTypes []SortType, withStats bool) []SortType {
+	if !withStats {
+		return sortTypes
+	}
+	return append(append(make([]SortType, 0, len(sortTypes)+len(statsSortTypes)), sortTypes...), statsSortTypes...)
+}
+
+func sortColumnOf(sortType SortType, sortTypes []SortType, columns []int) int {
+	for i, typ := range sortTypes {
+		if typ == sortType {
+			return columns[i]
+		}
+	}
+	return -1
+}
+
+// less compares with the given comparison result and falls back to the names
+// in ascending to keep the order of the rows stable.
+func less(order SortOrder, cmp, nameCmp int) bool {
+	if cmp == 0 {
+		return nameCmp < 0
+	}
+	if order == Descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compareValue(a, b corev1.ResourceList, typ corev1.ResourceName) int {
+	x, _ := GetRawResourceValue(a, typ)
+	y, _ := GetRawResourceValue(b, typ)
+	return compareFloat(float64(x), float64(y))
+}
+
+// comparePercentage compares the usages against the limits,
+// where the ones without the usage or the limit are the lowest.
+func comparePercentage(usageA, limitsA, usageB, limitsB corev1.ResourceList, name corev1.ResourceName) int {
+	x, _ := usagePercentage(usageA, limitsA, name)
+	y, _ := usagePercentage(usageB, limitsB, name)
+	return compareFloat(x, y)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareString(a, b string) int {
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"fmt"
+	"image"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// width of each column of the stats
+	statsColumnWidth = 10
+)
+
+var (
+	// columns of the stats of the kubelets, which are appended to the tables if reported
+	nodeStatsHeader            = []string{"NET(RX)", "NET(TX)", "%FS", "%Inodes"}
+	nodeStatsSortTypes         = []SortType{ByNetworkRx, ByNetworkTx, ByFsPercentage}
+	nodeStatsSortColumns       = []int{11, 12, 13}
+	summarizedStatsHeader      = []string{"NET(RX)", "NET(TX)", "FS(U)", "FS(L)"}
+	summarizedStatsSortTypes   = []SortType{ByNetworkRx, ByNetworkTx, ByFsUsage}
+	summarizedStatsSortColumns = []int{15, 16, 17}
+	allStatsHeader             = []string{"FS(U)", "Logs(U)", "FS(L)"}
+	allStatsSortTypes          = []SortType{ByFsUsage}
+	allStatsSortColumns        = []int{16}
+)
+
+// NetworkStats is the rates of the traffic of the network in bytes per second.
+type NetworkStats struct {
+	RxRate float64
+	TxRate float64
+}
+
+// FsStats is the usage of a filesystem, whose capacity is zero if unknown.
+type FsStats struct {
+	UsedBytes     uint64
+	CapacityBytes uint64
+	InodesUsed    uint64
+	Inodes        uint64
+}
+
+// rxRate returns the received rate in Ki/s.
+func (n *NetworkStats) rxRate() (float64, string, bool) {
+	if n == nil {
+		return 0, "-", false
+	}
+	return n.RxRate / 1024, rateString(n.RxRate), true
+}
+
+// txRate returns the transmitted rate in Ki/s.
+func (n *NetworkStats) txRate() (float64, string, bool) {
+	if n == nil {
+		return 0, "-", false
+	}
+	return n.TxRate / 1024, rateString(n.TxRate), true
+}
+
+func rateString(bytesPerSecond float64) string {
+	return fmt.Sprintf("%vKi/s", int64(bytesPerSecond/1024))
+}
+
+// used returns the used bytes in Mi.
+func (f *FsStats) used() (float64, string, bool) {
+	if f == nil {
+		return 0, "-", false
+	}
+	return float64(f.UsedBytes / (1024 * 1024)), fmt.Sprintf("%vMi", f.UsedBytes/(1024*1024)), true
+}
+
+func (f *FsStats) usedPercentage() (float64, string, bool) {
+	if f == nil || f.CapacityBytes == 0 {
+		return 0, "-", false
+	}
+	return percentage(f.UsedBytes, f.CapacityBytes)
+}
+
+func (f *FsStats) inodesUsed() (float64, string, bool) {
+	if f == nil {
+		return 0, "-", false
+	}
+	return float64(f.InodesUsed), fmt.Sprintf("%v", f.InodesUsed), true
+}
+
+func (f *FsStats) inodesPercentage() (float64, string, bool) {
+	if f == nil || f.Inodes == 0 {
+		return 0, "-", false
+	}
+	return percentage(f.InodesUsed, f.Inodes)
+}
+
+func percentage(used, total uint64) (float64, string, bool) {
+	p := float64(used) / float64(total) * 100
+	return p, fmt.Sprintf("%v%%", int(p)), true
+}
+
+// ephemeralStorageLimit returns the limit of the ephemeral storage in Mi.
+func ephemeralStorageLimit(limits corev1.ResourceList) (float64, string, bool) {
+	val, ok := limits[corev1.ResourceEphemeralStorage]
+	if !ok {
+		return 0, "-", false
+	}
+	mi := val.Value() / (1024 * 1024)
+	return float64(mi), fmt.Sprintf("%vMi", mi), true
+}
+
+// hasStats tells whether any of the rows has the stats of the kubelets.
+func hasStats(rows int, has func(int) bool) bool {
+	for i := 0; i < rows; i++ {
+		if has(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// statsRect narrows the rect for the columns followed by the stats.
+func statsRect(rect image.Rectangle, statsHeader []string) image.Rectangle {
+	rect.Max.X -= len(statsHeader) * statsColumnWidth
+	return rect
+}
+
+// appendStatsColumns appends the columns of the stats to the header.
+func appendStatsColumns(header []string, widths []int, statsHeader []string) ([]string, []int) {
+	header = append(append(make([]string, 0, len(header)+len(statsHeader)), header...), statsHeader...)
+	for range statsHeader {
+		widths = append(widths, statsColumnWidth)
+	}
+	return header, widths
+}
+
+// statsSortColumnOf returns the column of the sort type in the table with the stats.
+func statsSortColumnOf(sortType SortType, withStats bool, sortTypes []SortType, columns []int, statsSortTypes []SortType, statsColumns []int) int {
+	if column := sortColumnOf(sortType, sortTypes, columns); column >= 0 || !withStats {
+		return column
+	}
+	return sortColumnOf(sortType, statsSortTypes, statsColumns)
+}
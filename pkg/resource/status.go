@@ -0,0 +1,209 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// RowState tells whether a row of tables needs attention.
+type RowState int
+
+const (
+	RowNormal RowState = iota
+	RowWarning
+	RowError
+)
+
+const (
+	// restarts which are terminated within the window are warned
+	recentRestartWindow = 10 * time.Minute
+)
+
+// status is the state of a pod or a container derived from the container statuses.
+type status struct {
+	phase     corev1.PodPhase
+	reason    string
+	ready     int
+	total     int
+	restarts  int32
+	createdAt time.Time
+	// reason and time of the last termination
+	lastReason     string
+	lastFinishedAt time.Time
+}
+
+// newPodStatus derives the status of the pod in the same way as kubectl.
+func newPodStatus(p corev1.Pod) status {
+	s := status{
+		phase:     p.Status.Phase,
+		reason:    string(p.Status.Phase),
+		total:     len(p.Spec.Containers),
+		createdAt: p.CreationTimestamp.Time,
+	}
+	if p.Status.Reason != "" {
+		s.reason = p.Status.Reason
+	}
+	if s.reason == string(corev1.PodPending) {
+		// e.g. Unschedulable
+		for _, cond := range p.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason != "" {
+				s.reason = cond.Reason
+				break
+			}
+		}
+	}
+
+	initializing := false
+	for i, cs := range p.Status.InitContainerStatuses {
+		s.restarts += cs.RestartCount
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			continue
+		case cs.State.Terminated != nil:
+			s.reason = "Init:" + terminatedReason(cs.State.Terminated)
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing":
+			s.reason = "Init:" + cs.State.Waiting.Reason
+		default:
+			s.reason = fmt.Sprintf("Init:%d/%d", i, len(p.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	hasRunning := false
+	for i := len(p.Status.ContainerStatuses) - 1; i >= 0; i-- {
+		cs := p.Status.ContainerStatuses[i]
+		s.restarts += cs.RestartCount
+		if cs.Ready {
+			s.ready++
+		}
+		s.setLastTermination(cs)
+		if initializing {
+			continue
+		}
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "":
+			s.reason = cs.State.Waiting.Reason
+		case cs.State.Terminated != nil:
+			s.reason = terminatedReason(cs.State.Terminated)
+		case cs.Ready && cs.State.Running != nil:
+			hasRunning = true
+		}
+	}
+	if s.reason == "Completed" && hasRunning {
+		s.reason = string(corev1.PodRunning)
+	}
+
+	if p.DeletionTimestamp != nil {
+		s.reason = "Terminating"
+		if p.Status.Reason == "NodeLost" {
+			s.reason = "Unknown"
+		}
+	}
+	return s
+}
+
+// newContainerStatus derives the status of the container in the pod.
+func newContainerStatus(p corev1.Pod, name string) status {
+	s := status{
+		phase:     p.Status.Phase,
+		reason:    string(p.Status.Phase),
+		total:     1,
+		createdAt: p.CreationTimestamp.Time,
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.Name != name {
+			continue
+		}
+		s.restarts = cs.RestartCount
+		if cs.Ready {
+			s.ready = 1
+		}
+		s.setLastTermination(cs)
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "":
+			s.reason = cs.State.Waiting.Reason
+		case cs.State.Terminated != nil:
+			s.reason = terminatedReason(cs.State.Terminated)
+		case cs.State.Running != nil:
+			s.reason = string(corev1.PodRunning)
+		}
+	}
+	if p.DeletionTimestamp != nil {
+		s.reason = "Terminating"
+	}
+	return s
+}
+
+// setLastTermination keeps the latest termination of the containers.
+func (s *status) setLastTermination(cs corev1.ContainerStatus) {
+	last := cs.LastTerminationState.Terminated
+	if last == nil || last.FinishedAt.Time.Before(s.lastFinishedAt) {
+		return
+	}
+	s.lastReason = terminatedReason(last)
+	s.lastFinishedAt = last.FinishedAt.Time
+}
+
+func terminatedReason(t *corev1.ContainerStateTerminated) string {
+	switch {
+	case t.Reason != "":
+		return t.Reason
+	case t.Signal != 0:
+		return fmt.Sprintf("Signal:%d", t.Signal)
+	default:
+		return fmt.Sprintf("ExitCode:%d", t.ExitCode)
+	}
+}
+
+func (s status) readyString() string {
+	return fmt.Sprintf("%d/%d", s.ready, s.total)
+}
+
+func (s status) restartsString() string {
+	return fmt.Sprintf("%d", s.restarts)
+}
+
+func (s status) lastReasonString() string {
+	if s.lastReason == "" {
+		return "-"
+	}
+	return s.lastReason
+}
+
+func (s status) ageString(now time.Time) string {
+	if s.createdAt.IsZero() {
+		return "-"
+	}
+	return duration.HumanDuration(now.Sub(s.createdAt))
+}
+
+// rowState tells pods which are not running healthy, or restarted recently.
+func (s status) rowState(now time.Time) RowState {
+	switch s.reason {
+	case string(corev1.PodRunning):
+		if s.ready < s.total {
+			return RowWarning
+		}
+		if !s.lastFinishedAt.IsZero() && now.Sub(s.lastFinishedAt) < recentRestartWindow {
+			return RowWarning
+		}
+		return RowNormal
+	case string(corev1.PodSucceeded), "Completed":
+		return RowNormal
+	case string(corev1.PodPending), "ContainerCreating", "PodInitializing", "Terminating":
+		return RowWarning
+	default:
+		// e.g. Init:0/1 is in progress, but Init:CrashLoopBackOff is not
+		if progress := strings.TrimPrefix(s.reason, "Init:"); progress != s.reason &&
+			progress != "" && unicode.IsDigit(rune(progress[0])) {
+			return RowWarning
+		}
+		return RowError
+	}
+}
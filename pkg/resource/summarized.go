@@ -1,54 +1,212 @@
 package resource
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
 
 	. "github.com/ynqa/ktop/pkg/util"
 )
 
 type SummarizedResource struct {
-	podName  string
-	nodeName string
-	logs     string
-	usage    corev1.ResourceList
+	namespace string
+	podName   string
+	nodeName  string
+	usage     corev1.ResourceList
+	limits    corev1.ResourceList
+	requests  corev1.ResourceList
+	status    status
+	// stats of the kubelet
+	network   *NetworkStats
+	ephemeral *FsStats
 }
 
-func NewSummarizedResource(p corev1.Pod, sumUsage corev1.ResourceList, logs string) *SummarizedResource {
+func NewSummarizedResource(p corev1.Pod, sumUsage corev1.ResourceList) *SummarizedResource {
 	return &SummarizedResource{
-		podName:  p.Name,
-		nodeName: p.Spec.NodeName,
-		logs:     logs,
-		usage:    sumUsage,
+		namespace: p.Namespace,
+		podName:   p.Name,
+		nodeName:  p.Spec.NodeName,
+		usage:     sumUsage,
+		limits:    sumResourceLists(p.Spec.Containers, func(c corev1.Container) corev1.ResourceList { return c.Resources.Limits }),
+		requests:  sumResourceLists(p.Spec.Containers, func(c corev1.Container) corev1.ResourceList { return c.Resources.Requests }),
+		status:    newPodStatus(p),
 	}
 }
 
+// sumResourceLists sums cpu, memory and ephemeral storage of the containers,
+// which are left unset if any container does not define them.
+func sumResourceLists(containers []corev1.Container, fn func(corev1.Container) corev1.ResourceList) corev1.ResourceList {
+	sum := make(corev1.ResourceList)
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		var total kr.Quantity
+		defined := len(containers) > 0
+		for _, c := range containers {
+			val, ok := fn(c)[name]
+			if !ok {
+				defined = false
+				break
+			}
+			total.Add(val)
+		}
+		if defined {
+			sum[name] = total
+		}
+	}
+	return sum
+}
+
 func (s *SummarizedResource) GetNodeName() string {
 	return s.nodeName
 }
 
+func (s *SummarizedResource) GetNamespace() string {
+	return s.namespace
+}
+
 func (s *SummarizedResource) GetPodName() string {
 	return s.podName
 }
 
+// HasUsage returns whether the metrics of the pod are reported.
+func (s *SummarizedResource) HasUsage() bool {
+	_, ok := s.usage[corev1.ResourceCPU]
+	return ok
+}
+
 func (s *SummarizedResource) GetCpuUsage() (float64, string) {
 	return GetResourceValue(s.usage, corev1.ResourceCPU),
 		GetResourceValueString(s.usage, corev1.ResourceCPU)
 }
 
-func (s *SummarizedResource) GetLogs() string {
-	return s.logs
-}
-
 func (s *SummarizedResource) GetMemoryUsage() (float64, string) {
 	return GetResourceValue(s.usage, corev1.ResourceMemory),
 		GetResourceValueString(s.usage, corev1.ResourceMemory)
 }
 
-// header: "POD", "%CPU", "%MEM"
+func (s *SummarizedResource) GetCpuLimits() (float64, string, bool) {
+	return getOptionalValue(s.limits, corev1.ResourceCPU)
+}
+
+func (s *SummarizedResource) GetCpuRequests() (float64, string, bool) {
+	return getOptionalValue(s.requests, corev1.ResourceCPU)
+}
+
+func (s *SummarizedResource) GetMemoryLimits() (float64, string, bool) {
+	return getOptionalValue(s.limits, corev1.ResourceMemory)
+}
+
+func (s *SummarizedResource) GetMemoryRequests() (float64, string, bool) {
+	return getOptionalValue(s.requests, corev1.ResourceMemory)
+}
+
+// SetStats sets the stats reported by the kubelet.
+func (s *SummarizedResource) SetStats(network *NetworkStats, ephemeral *FsStats) {
+	s.network = network
+	s.ephemeral = ephemeral
+}
+
+func (s *SummarizedResource) hasStats() bool {
+	return s.network != nil || s.ephemeral != nil
+}
+
+func (s *SummarizedResource) GetRxRate() (float64, string, bool) {
+	return s.network.rxRate()
+}
+
+func (s *SummarizedResource) GetTxRate() (float64, string, bool) {
+	return s.network.txRate()
+}
+
+// GetFsUsage returns the usage of the ephemeral storage,
+// i.e. the writable layers, the logs and the emptyDir volumes.
+func (s *SummarizedResource) GetFsUsage() (float64, string, bool) {
+	return s.ephemeral.used()
+}
+
+// GetFsLimits returns the limit of the ephemeral storage.
+func (s *SummarizedResource) GetFsLimits() (float64, string, bool) {
+	return ephemeralStorageLimit(s.limits)
+}
+
+func (s *SummarizedResource) GetInodesUsage() (float64, string, bool) {
+	return s.ephemeral.inodesUsed()
+}
+
+// getOptionalValue returns the value with whether it is defined.
+func getOptionalValue(lst corev1.ResourceList, name corev1.ResourceName) (float64, string, bool) {
+	_, ok := lst[name]
+	return GetResourceValue(lst, name), GetResourceValueString(lst, name), ok
+}
+
+func (s *SummarizedResource) compare(other *SummarizedResource, sortType SortType) int {
+	switch sortType {
+	case ByNodeName:
+		return compareString(s.nodeName, other.nodeName)
+	case ByRestarts:
+		return compareFloat(float64(s.status.restarts), float64(other.status.restarts))
+	case ByCPUUsage:
+		return compareValue(s.usage, other.usage, corev1.ResourceCPU)
+	case ByCPULimit:
+		return compareValue(s.limits, other.limits, corev1.ResourceCPU)
+	case ByCPURequest:
+		return compareValue(s.requests, other.requests, corev1.ResourceCPU)
+	case ByCPUPercentage:
+		return comparePercentage(s.usage, s.limits, other.usage, other.limits, corev1.ResourceCPU)
+	case ByMemoryUsage:
+		return compareValue(s.usage, other.usage, corev1.ResourceMemory)
+	case ByMemoryLimit:
+		return compareValue(s.limits, other.limits, corev1.ResourceMemory)
+	case ByMemoryRequest:
+		return compareValue(s.requests, other.requests, corev1.ResourceMemory)
+	case ByMemoryPercentage:
+		return comparePercentage(s.usage, s.limits, other.usage, other.limits, corev1.ResourceMemory)
+	case ByNetworkRx:
+		x, _, _ := s.GetRxRate()
+		y, _, _ := other.GetRxRate()
+		return compareFloat(x, y)
+	case ByNetworkTx:
+		x, _, _ := s.GetTxRate()
+		y, _, _ := other.GetTxRate()
+		return compareFloat(x, y)
+	case ByFsUsage:
+		x, _, _ := s.GetFsUsage()
+		y, _, _ := other.GetFsUsage()
+		return compareFloat(x, y)
+	default:
+		if cmp := compareString(s.namespace, other.namespace); cmp != 0 {
+			return cmp
+		}
+		return compareString(s.podName, other.podName)
+	}
+}
+
+// header: "NAMESPACE", "POD", "STATUS", "READY", "RESTARTS", "LAST REASON", "AGE", "CPU(U)", "CPU(L)", "CPU(R)", "%CPU", "Memory(U)", "Memory(L)", "Memory(R)", "%Memory"
 func (s *SummarizedResource) toRow() []string {
 	return []string{
+		s.namespace,
 		s.podName,
+		s.status.reason,
+		s.status.readyString(),
+		s.status.restartsString(),
+		s.status.lastReasonString(),
+		s.status.ageString(time.Now()),
 		GetResourceValueString(s.usage, corev1.ResourceCPU),
+		GetResourceValueString(s.limits, corev1.ResourceCPU),
+		GetResourceValueString(s.requests, corev1.ResourceCPU),
+		limitPercentageString(s.usage, s.limits, corev1.ResourceCPU),
 		GetResourceValueString(s.usage, corev1.ResourceMemory),
+		GetResourceValueString(s.limits, corev1.ResourceMemory),
+		GetResourceValueString(s.requests, corev1.ResourceMemory),
+		limitPercentageString(s.usage, s.limits, corev1.ResourceMemory),
 	}
 }
+
+// header: "NET(RX)", "NET(TX)", "FS(U)", "FS(L)"
+func (s *SummarizedResource) toStatsRow() []string {
+	_, rx, _ := s.GetRxRate()
+	_, tx, _ := s.GetTxRate()
+	_, fs, _ := s.GetFsUsage()
+	_, limit, _ := s.GetFsLimits()
+	return []string{rx, tx, fs, limit}
+}
@@ -3,6 +3,7 @@ package resource
 import (
 	"image"
 	"sort"
+	"time"
 
 	. "github.com/ynqa/ktop/pkg/util"
 )
@@ -10,36 +11,64 @@ import (
 var (
 	summarizedTitle  = "⎈ Pod ⎈"
 	summarizedHeader = []string{
-		"POD", "CPU(U)", "Memory(U)",
+		"NAMESPACE", "POD", "STATUS", "READY", "RESTARTS", "LAST REASON", "AGE",
+		"CPU(U)", "CPU(L)", "CPU(R)", "%CPU",
+		"Memory(U)", "Memory(L)", "Memory(R)", "%Memory",
 	}
-	summarizedWidthFn = func(rect image.Rectangle, maxLen int) []int {
-		nameWidth := IntMax(50, IntMin(rect.Dx()-20, maxLen+indentSize))
-		return []int{nameWidth, 10, 10}
+	summarizedWidthFn = func(rect image.Rectangle, maxLen0, maxLen1 int) []int {
+		namespaceWidth := IntMax(namespaceMinWidth, IntMin(rect.Dx()-137, maxLen0+indentSize))
+		nameWidth := IntMax(50, IntMin(rect.Dx()-137, maxLen1+indentSize))
+		widths := append([]int{namespaceWidth, nameWidth}, statusWidths...)
+		return append(widths, 10, 10, 10, 10, 10, 10, 10, 10)
 	}
+	summarizedSortTypes = []SortType{
+		ByName, ByNodeName, ByRestarts,
+		ByCPUUsage, ByCPULimit, ByCPURequest, ByCPUPercentage,
+		ByMemoryUsage, ByMemoryLimit, ByMemoryRequest, ByMemoryPercentage,
+	}
+	summarizedSortColumns = []int{1, -1, 4, 7, 8, 9, 10, 11, 12, 13, 14}
+	// columns of the percentages of cpu and memory usages
+	summarizedUsageColumns = []int{10, 14}
 )
 
-func AsSummarizedTableViewer(resources []*SummarizedResource, sortType SortType) ResourceTableViewer {
-	switch sortType {
-	case ByName:
-		return sortByNameForSummarized(resources)
-	default:
-		return sortByNameForSummarized(resources)
+func AsSummarizedTableViewer(resources []*SummarizedResource, sortType SortType, order SortOrder) ResourceTableViewer {
+	return &summarizedTableViewer{
+		resources: resources,
+		sortType:  sortType,
+		order:     order,
+		withStats: hasStats(len(resources), func(i int) bool { return resources[i].hasStats() }),
 	}
 }
 
-type sortByNameForSummarized []*SummarizedResource
+type summarizedTableViewer struct {
+	resources []*SummarizedResource
+	sortType  SortType
+	order     SortOrder
+	// whether the stats of the kubelets are shown as columns
+	withStats bool
+}
 
-func (s sortByNameForSummarized) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
-	rows := make([][]string, len(s))
-	var maxLen int
-	for i, v := range s {
-		rows[i] = v.toRow()
-		maxLen = IntMax(maxLen, len(rows[i][0]))
+func (v *summarizedTableViewer) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
+	rows := make([][]string, len(v.resources))
+	var maxLen0, maxLen1 int
+	for i, r := range v.resources {
+		rows[i] = r.toRow()
+		if v.withStats {
+			rows[i] = append(rows[i], r.toStatsRow()...)
+		}
+		maxLen0 = IntMax(maxLen0, len(rows[i][0]))
+		maxLen1 = IntMax(maxLen1, len(rows[i][1]))
+	}
+	if v.withStats {
+		rect = statsRect(rect, summarizedStatsHeader)
 	}
 	title, header, widths :=
-		summarizedTitle, summarizedHeader, summarizedWidthFn(rect, maxLen)
+		summarizedTitle, summarizedHeader, summarizedWidthFn(rect, maxLen0, maxLen1)
+	if v.withStats {
+		header, widths = appendStatsColumns(header, widths, summarizedStatsHeader)
+	}
 
-	if len(s) == 0 {
+	if len(v.resources) == 0 {
 		header = emptyHeader
 		widths = emptyWidthFn(rect)
 		rows = emptyRows
@@ -47,8 +76,49 @@ func (s sortByNameForSummarized) GetTableShape(rect image.Rectangle) (string, []
 	return title, header, widths, rows
 }
 
-func (s sortByNameForSummarized) SortRows() {
-	sort.Slice(s, func(i, j int) bool {
-		return s[i].podName < s[j].podName
+func (v *summarizedTableViewer) GetRecords(timestamp time.Time) ([]string, []Record) {
+	records := make([]Record, len(v.resources))
+	for i, r := range v.resources {
+		records[i] = r.toRecord(timestamp)
+	}
+	return podRecordHeader, records
+}
+
+func (v *summarizedTableViewer) GetSortColumn() int {
+	if len(v.resources) == 0 {
+		return -1
+	}
+	return statsSortColumnOf(v.sortType, v.withStats, summarizedSortTypes, summarizedSortColumns, summarizedStatsSortTypes, summarizedStatsSortColumns)
+}
+
+func (v *summarizedTableViewer) GetRowStates() []RowState {
+	now := time.Now()
+	states := make([]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = r.status.rowState(now)
+	}
+	return states
+}
+
+func (v *summarizedTableViewer) GetCellStates(thresholds Thresholds) [][]RowState {
+	states := make([][]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = thresholds.cellStates(v.columns(), r.usage, r.limits, summarizedUsageColumns)
+	}
+	return states
+}
+
+func (v *summarizedTableViewer) SortRows() {
+	sort.SliceStable(v.resources, func(i, j int) bool {
+		a, b := v.resources[i], v.resources[j]
+		return less(v.order, a.compare(b, v.sortType), a.compare(b, ByName))
 	})
 }
+
+// columns returns the number of the columns including the stats.
+func (v *summarizedTableViewer) columns() int {
+	if v.withStats {
+		return len(summarizedHeader) + len(summarizedStatsHeader)
+	}
+	return len(summarizedHeader)
+}
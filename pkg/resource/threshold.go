@@ -0,0 +1,62 @@
+package resource
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+// Thresholds are the percentages of the usages against the limits,
+// or against the allocatable for nodes, above which the cells are highlighted.
+// Zero disables the threshold.
+type Thresholds struct {
+	CPUWarning     float64
+	CPUCritical    float64
+	MemoryWarning  float64
+	MemoryCritical float64
+}
+
+func (t Thresholds) stateOf(name corev1.ResourceName, percentage float64) RowState {
+	warning, critical := t.CPUWarning, t.CPUCritical
+	if name == corev1.ResourceMemory {
+		warning, critical = t.MemoryWarning, t.MemoryCritical
+	}
+	switch {
+	case critical > 0 && percentage >= critical:
+		return RowError
+	case warning > 0 && percentage >= warning:
+		return RowWarning
+	default:
+		return RowNormal
+	}
+}
+
+// cellStates returns the states of the cells of a row, where the usages of cpu and memory
+// are shown in the given columns.
+func (t Thresholds) cellStates(columns int, usage, limits corev1.ResourceList, usageColumns []int) []RowState {
+	states := make([]RowState, columns)
+	for i, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if percentage, ok := usagePercentage(usage, limits, name); ok {
+			states[usageColumns[i]] = t.stateOf(name, percentage)
+		}
+	}
+	return states
+}
+
+// usagePercentage returns the usage against the limit if both of them are defined.
+func usagePercentage(usage, limits corev1.ResourceList, name corev1.ResourceName) (float64, bool) {
+	val, ok := usage[name]
+	limit, lok := limits[name]
+	if !ok || !lok || limit.IsZero() {
+		return 0, false
+	}
+	return GetResourcePercentage(val, limit), true
+}
+
+// limitPercentageString leaves the percentage unset without the usage or the limit.
+func limitPercentageString(usage, limits corev1.ResourceList, name corev1.ResourceName) string {
+	if _, ok := usagePercentage(usage, limits, name); !ok {
+		return "-"
+	}
+	return GetResourcePercentageString(usage[name], limits[name])
+}
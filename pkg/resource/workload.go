@@ -0,0 +1,170 @@
+package resource
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kr "k8s.io/apimachinery/pkg/api/resource"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+type WorkloadResource struct {
+	namespace string
+	kind      string
+	name      string
+	replicas  int
+	usage     corev1.ResourceList
+	limits    corev1.ResourceList
+	requests  corev1.ResourceList
+}
+
+func NewWorkloadResource(namespace, kind, name string) *WorkloadResource {
+	return &WorkloadResource{
+		namespace: namespace,
+		kind:      kind,
+		name:      name,
+		usage:     make(corev1.ResourceList),
+		limits:    make(corev1.ResourceList),
+		requests:  make(corev1.ResourceList),
+	}
+}
+
+// Add sums up the pod as a replica of the workload.
+// Limits and requests are left unset if any replica does not define them.
+func (w *WorkloadResource) Add(pod *SummarizedResource) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		addQuantity(w.usage, pod.usage, name, true)
+		addQuantity(w.limits, pod.limits, name, w.replicas == 0)
+		addQuantity(w.requests, pod.requests, name, w.replicas == 0)
+	}
+	w.replicas++
+}
+
+func addQuantity(sum, lst corev1.ResourceList, name corev1.ResourceName, first bool) {
+	val, ok := lst[name]
+	if !ok {
+		if !first {
+			delete(sum, name)
+		}
+		return
+	}
+	total, defined := sum[name]
+	if !defined && !first {
+		return
+	}
+	if !defined {
+		total = kr.Quantity{}
+	}
+	total.Add(val)
+	sum[name] = total
+}
+
+func (w *WorkloadResource) GetNamespace() string {
+	return w.namespace
+}
+
+func (w *WorkloadResource) GetKind() string {
+	return w.kind
+}
+
+func (w *WorkloadResource) GetWorkloadName() string {
+	return w.name
+}
+
+func (w *WorkloadResource) GetReplicas() int {
+	return w.replicas
+}
+
+// HasUsage returns whether the metrics of any pod of the workload are reported.
+func (w *WorkloadResource) HasUsage() bool {
+	_, ok := w.usage[corev1.ResourceCPU]
+	return ok
+}
+
+func (w *WorkloadResource) GetCpuUsage() (float64, string) {
+	return GetResourceValue(w.usage, corev1.ResourceCPU),
+		GetResourceValueString(w.usage, corev1.ResourceCPU)
+}
+
+func (w *WorkloadResource) GetMemoryUsage() (float64, string) {
+	return GetResourceValue(w.usage, corev1.ResourceMemory),
+		GetResourceValueString(w.usage, corev1.ResourceMemory)
+}
+
+func (w *WorkloadResource) GetCpuLimits() (float64, string, bool) {
+	_, ok := w.limits[corev1.ResourceCPU]
+	str := GetResourceValueString(w.limits, corev1.ResourceCPU)
+	return GetResourceValue(w.limits, corev1.ResourceCPU), str, ok
+}
+
+func (w *WorkloadResource) GetMemoryLimits() (float64, string, bool) {
+	_, ok := w.limits[corev1.ResourceMemory]
+	str := GetResourceValueString(w.limits, corev1.ResourceMemory)
+	return GetResourceValue(w.limits, corev1.ResourceMemory), str, ok
+}
+
+func (w *WorkloadResource) GetCpuRequests() (float64, string, bool) {
+	return getOptionalValue(w.requests, corev1.ResourceCPU)
+}
+
+func (w *WorkloadResource) GetMemoryRequests() (float64, string, bool) {
+	return getOptionalValue(w.requests, corev1.ResourceMemory)
+}
+
+// average returns the usage per replica.
+func (w *WorkloadResource) average(name corev1.ResourceName) corev1.ResourceList {
+	val, ok := w.usage[name]
+	if !ok || w.replicas == 0 {
+		return corev1.ResourceList{}
+	}
+	var avg *kr.Quantity
+	if name == corev1.ResourceCPU {
+		avg = kr.NewMilliQuantity(val.MilliValue()/int64(w.replicas), val.Format)
+	} else {
+		avg = kr.NewQuantity(val.Value()/int64(w.replicas), val.Format)
+	}
+	return corev1.ResourceList{name: *avg}
+}
+
+func (w *WorkloadResource) compare(other *WorkloadResource, sortType SortType) int {
+	switch sortType {
+	case ByCPUUsage:
+		return compareValue(w.usage, other.usage, corev1.ResourceCPU)
+	case ByCPULimit:
+		return compareValue(w.limits, other.limits, corev1.ResourceCPU)
+	case ByCPURequest:
+		return compareValue(w.requests, other.requests, corev1.ResourceCPU)
+	case ByMemoryUsage:
+		return compareValue(w.usage, other.usage, corev1.ResourceMemory)
+	case ByMemoryLimit:
+		return compareValue(w.limits, other.limits, corev1.ResourceMemory)
+	case ByMemoryRequest:
+		return compareValue(w.requests, other.requests, corev1.ResourceMemory)
+	default:
+		if cmp := compareString(w.namespace, other.namespace); cmp != 0 {
+			return cmp
+		}
+		if cmp := compareString(w.kind, other.kind); cmp != 0 {
+			return cmp
+		}
+		return compareString(w.name, other.name)
+	}
+}
+
+// header: "NAMESPACE", "WORKLOAD", "PODS", "CPU(U)", "CPU(Avg)", "CPU(L)", "CPU(R)", "Memory(U)", "Memory(Avg)", "Memory(L)", "Memory(R)"
+func (w *WorkloadResource) toRow() []string {
+	return []string{
+		w.namespace,
+		fmt.Sprintf("%v/%v", w.kind, w.name),
+		fmt.Sprintf("%v", w.replicas),
+		GetResourceValueString(w.usage, corev1.ResourceCPU),
+		GetResourceValueString(w.average(corev1.ResourceCPU), corev1.ResourceCPU),
+		GetResourceValueString(w.limits, corev1.ResourceCPU),
+		GetResourceValueString(w.requests, corev1.ResourceCPU),
+		GetResourceValueString(w.usage, corev1.ResourceMemory),
+		GetResourceValueString(w.average(corev1.ResourceMemory), corev1.ResourceMemory),
+		GetResourceValueString(w.limits, corev1.ResourceMemory),
+		GetResourceValueString(w.requests, corev1.ResourceMemory),
+	}
+}
@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"image"
+	"sort"
+	"time"
+
+	. "github.com/ynqa/ktop/pkg/util"
+)
+
+var (
+	workloadTitle  = "⎈ Workload ⎈"
+	workloadHeader = []string{
+		"NAMESPACE", "WORKLOAD", "PODS",
+		"CPU(U)", "CPU(Avg)", "CPU(L)", "CPU(R)",
+		"Memory(U)", "Memory(Avg)", "Memory(L)", "Memory(R)",
+	}
+	workloadWidthFn = func(rect image.Rectangle, maxLen0, maxLen1 int) []int {
+		namespaceWidth := IntMax(namespaceMinWidth, IntMin(rect.Dx()-100, maxLen0+indentSize))
+		nameWidth := IntMax(40, IntMin(rect.Dx()-100, maxLen1+indentSize))
+		return []int{namespaceWidth, nameWidth, 6, 10, 10, 10, 10, 10, 12, 10, 10}
+	}
+	workloadSortTypes = []SortType{
+		ByName,
+		ByCPUUsage, ByCPULimit, ByCPURequest,
+		ByMemoryUsage, ByMemoryLimit, ByMemoryRequest,
+	}
+	workloadSortColumns = []int{1, 3, 5, 6, 7, 9, 10}
+	// columns of cpu and memory usages
+	workloadUsageColumns = []int{3, 7}
+)
+
+func AsWorkloadTableViewer(resources []*WorkloadResource, sortType SortType, order SortOrder) ResourceTableViewer {
+	return &workloadTableViewer{
+		resources: resources,
+		sortType:  sortType,
+		order:     order,
+	}
+}
+
+type workloadTableViewer struct {
+	resources []*WorkloadResource
+	sortType  SortType
+	order     SortOrder
+}
+
+func (v *workloadTableViewer) GetTableShape(rect image.Rectangle) (string, []string, []int, [][]string) {
+	rows := make([][]string, len(v.resources))
+	var maxLen0, maxLen1 int
+	for i, r := range v.resources {
+		rows[i] = r.toRow()
+		maxLen0 = IntMax(maxLen0, len(rows[i][0]))
+		maxLen1 = IntMax(maxLen1, len(rows[i][1]))
+	}
+	title, header, widths :=
+		workloadTitle, workloadHeader, workloadWidthFn(rect, maxLen0, maxLen1)
+
+	if len(v.resources) == 0 {
+		header = emptyHeader
+		widths = emptyWidthFn(rect)
+		rows = emptyRows
+	}
+	return title, header, widths, rows
+}
+
+func (v *workloadTableViewer) GetRecords(timestamp time.Time) ([]string, []Record) {
+	records := make([]Record, len(v.resources))
+	for i, r := range v.resources {
+		records[i] = r.toRecord(timestamp)
+	}
+	return workloadRecordHeader, records
+}
+
+func (v *workloadTableViewer) GetSortColumn() int {
+	if len(v.resources) == 0 {
+		return -1
+	}
+	return sortColumnOf(v.sortType, workloadSortTypes, workloadSortColumns)
+}
+
+func (v *workloadTableViewer) GetRowStates() []RowState {
+	return nil
+}
+
+func (v *workloadTableViewer) GetCellStates(thresholds Thresholds) [][]RowState {
+	states := make([][]RowState, len(v.resources))
+	for i, r := range v.resources {
+		states[i] = thresholds.cellStates(len(workloadHeader), r.usage, r.limits, workloadUsageColumns)
+	}
+	return states
+}
+
+func (v *workloadTableViewer) SortRows() {
+	sort.SliceStable(v.resources, func(i, j int) bool {
+		a, b := v.resources[i], v.resources[j]
+		return less(v.order, a.compare(b, v.sortType), a.compare(b, ByName))
+	})
+}
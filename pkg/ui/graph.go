@@ -1,121 +1,365 @@
 package ui
 
 import (
+	"fmt"
 	"image"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	. "github.com/gizak/termui/v3"
 )
 
+const (
+	// rows above the plot for the header and the labels
+	graphLabelRows = 2
+	// margin of the Y-axis over the peak
+	graphHeadroom = 1.1
+	// columns between labels on the X-axis
+	xAxisLabelGap = 4
+)
+
+// ScaleMode decides the top of the Y-axis.
+type ScaleMode int
+
+const (
+	// fit to the peak of the data
+	ScaleFitData ScaleMode = iota
+	// fit to the highest reference line, e.g. the limit
+	ScaleFitLimit
+	// fixed to Graph.FixedMax
+	ScaleFixed
+)
+
+func (s ScaleMode) String() string {
+	switch s {
+	case ScaleFitLimit:
+		return "limit"
+	case ScaleFixed:
+		return "fixed"
+	default:
+		return "data"
+	}
+}
+
+// Next returns the mode to switch to from the mode.
+func (s ScaleMode) Next() ScaleMode {
+	return (s + 1) % (ScaleFixed + 1)
+}
+
+func ParseScaleMode(s string) (ScaleMode, error) {
+	for _, mode := range []ScaleMode{ScaleFitData, ScaleFitLimit, ScaleFixed} {
+		if mode.String() == s {
+			return mode, nil
+		}
+	}
+	return ScaleFitData, fmt.Errorf("unknown scale mode: %v", s)
+}
+
+// Series is a named line of data, whose latest value is at the end.
+// NaN is a gap of the data, which is not drawn.
+type Series struct {
+	Label string
+	Data  []float64
+	Color Color
+}
+
+// ReferenceLine is a horizontal line to compare the data with, e.g. requests and limits.
+type ReferenceLine struct {
+	Value float64
+	Label string
+	Color Color
+}
+
 type Graph struct {
 	*Block
-	// plot data
-	Data           []float64
-	UpperLimit     float64
-	DrawUpperLimit bool
+	// plot data, which are overlaid and described in the legend
+	Series         []Series
+	ReferenceLines []ReferenceLine
 
 	// label
-	LabelHeader     string
-	LabelData       string
-	LabelUpperLimit string
+	LabelHeader string
+
+	// scale of the Y-axis
+	ScaleMode ScaleMode
+	FixedMax  float64
+	// format of the ticks on the Y-axis
+	YTickFormatter func(float64) string
+	// interval between the data to describe the X-axis, or 0 to hide it
+	Interval time.Duration
 
 	// color
-	DataColor      Color
-	LimitColor     Color
+	ClipColor      Color
+	AxisColor      Color
 	LabelNameColor Color
 }
 
 func NewGraph() *Graph {
 	return &Graph{
-		Block: NewBlock(),
-		Data:  make([]float64, 0),
+		Block:          NewBlock(),
+		YTickFormatter: func(v float64) string { return strconv.FormatFloat(v, 'f', 0, 64) },
+		ClipColor:      ColorRed,
+		AxisColor:      Theme.Default.Fg,
 	}
 }
 
 func (self *Graph) Reset() {
-	self.Data = make([]float64, 0)
-	self.UpperLimit = 0
+	self.Series = nil
+	self.ReferenceLines = nil
 	self.LabelHeader = ""
-	self.LabelData = ""
-	self.LabelUpperLimit = ""
 }
 
-func (self *Graph) calcHeight(val float64) int {
-	return int((val / self.UpperLimit) * float64(self.Inner.Dy()-5))
+// latest returns the latest data of each series up to the width,
+// and the number of columns to align the latest data at the same column.
+func (self *Graph) latest(width int) ([][]float64, int) {
+	datas := make([][]float64, len(self.Series))
+	columns := 0
+	for i, series := range self.Series {
+		data := series.Data
+		if len(data) > width {
+			data = data[len(data)-width:]
+		}
+		datas[i] = data
+		columns = MaxInt(columns, len(data))
+	}
+	return datas, columns
+}
+
+// scaleMax returns the top of the Y-axis for the data in the scale mode.
+func (self *Graph) scaleMax(datas [][]float64) float64 {
+	if self.ScaleMode == ScaleFixed && self.FixedMax > 0 {
+		return self.FixedMax
+	}
+	peak := 0.
+	if self.ScaleMode == ScaleFitLimit {
+		for _, line := range self.ReferenceLines {
+			if line.Value > peak {
+				peak = line.Value
+			}
+		}
+	}
+	// fall back to the data without the limits
+	if peak <= 0 {
+		for _, data := range datas {
+			for _, v := range data {
+				if v > peak {
+					peak = v
+				}
+			}
+		}
+	}
+	if peak <= 0 {
+		return 1
+	}
+	return peak * graphHeadroom
+}
+
+// pointY returns the Y coordinate of the value on the canvas, which has 4 dots in each cell.
+func pointY(plot image.Rectangle, val, max float64) int {
+	ratio := val / max
+	if ratio < 0 {
+		ratio = 0
+	} else if ratio > 1 {
+		ratio = 1
+	}
+	return plot.Max.Y*4 - 1 - int(ratio*float64(plot.Dy()*4-1))
 }
 
 func (self *Graph) Draw(buf *Buffer) {
 	self.Block.Draw(buf)
 
-	// describe graph
-	if len(self.Data) != 0 {
+	plot := image.Rect(
+		self.Inner.Min.X,
+		self.Inner.Min.Y+graphLabelRows,
+		self.Inner.Max.X,
+		self.Inner.Max.Y,
+	)
+	// reserve the bottom row for the X-axis
+	drawXAxis := self.Interval > 0 && plot.Dy() >= 3
+	if drawXAxis {
+		plot.Max.Y--
+	}
+	max := 1.
+	if datas, columns := self.latest(plot.Dx()); plot.Dy() > 0 && columns != 0 {
+		max = self.scaleMax(datas)
+		plot.Min.X += self.drawAxis(buf, plot, max)
+		// use latest data, which may be narrowed after the Y-axis is placed
+		datas, columns = self.latest(plot.Dx())
+		if drawXAxis {
+			self.drawXAxis(buf, plot, columns)
+		}
+
 		canvas := NewCanvas()
-		canvas.Rectangle = self.Inner
-		// draw upper limit
-		if self.DrawUpperLimit {
-			limitHeight := self.calcHeight(self.UpperLimit)
+		canvas.Rectangle = plot
+		// draw reference lines, except for the ones above the top
+		for _, line := range self.ReferenceLines {
+			if line.Value > max {
+				continue
+			}
+			y := pointY(plot, line.Value, max)
 			canvas.SetLine(
-				image.Pt(
-					self.Inner.Min.X*2,
-					(self.Inner.Max.Y-limitHeight-1)*4,
-				),
-				image.Pt(
-					self.Inner.Max.X*2,
-					(self.Inner.Max.Y-limitHeight-1)*4,
-				),
-				self.LimitColor,
+				image.Pt(plot.Min.X*2, y),
+				image.Pt(plot.Max.X*2-1, y),
+				line.Color,
 			)
 		}
 
-		// use latest data
-		data := self.Data
-		if len(self.Data) > self.Inner.Dx() {
-			data = data[len(self.Data)-1-self.Inner.Dx() : len(self.Data)-1]
-		}
-		previousHeight := self.calcHeight(data[len(data)-1])
-		for i := len(data) - 1; i >= 0; i-- {
-			height := self.calcHeight(data[i])
-			// draw data
-			canvas.SetLine(
-				image.Pt(
-					(self.Inner.Min.X+i)*2,
-					(self.Inner.Max.Y-previousHeight-1)*4,
-				),
-				image.Pt(
-					(self.Inner.Min.X+i+1)*2,
-					(self.Inner.Max.Y-height-1)*4,
-				),
-				self.DataColor,
-			)
-			previousHeight = height
+		// draw data, which is clipped at the top
+		for i, data := range datas {
+			color := self.Series[i].Color
+			dataColor := func(vals ...float64) Color {
+				for _, v := range vals {
+					if v > max {
+						return self.ClipColor
+					}
+				}
+				return color
+			}
+			// the latest data of all series are on the same column
+			left := plot.Min.X + columns - len(data)
+			for j := range data {
+				if math.IsNaN(data[j]) {
+					continue
+				}
+				// the data are not connected over the gaps
+				if j > 0 && !math.IsNaN(data[j-1]) {
+					canvas.SetLine(
+						image.Pt((left+j-1)*2, pointY(plot, data[j-1], max)),
+						image.Pt((left+j)*2, pointY(plot, data[j], max)),
+						dataColor(data[j-1], data[j]),
+					)
+				} else if j == len(data)-1 || math.IsNaN(data[j+1]) {
+					canvas.SetPoint(image.Pt((left+j)*2, pointY(plot, data[j], max)), dataColor(data[j]))
+				}
+			}
 		}
 		canvas.Draw(buf)
 	}
 
 	// describe labels
-	stage := 1
-	if self.Inner.Dy() >= 3 {
+	if self.Inner.Dy() >= graphLabelRows {
 		if self.LabelHeader != "" {
 			buf.SetString(
-				self.LabelHeader,
+				TrimString(self.LabelHeader, self.Inner.Dx()-1),
 				NewStyle(self.LabelNameColor, ColorClear, ModifierBold),
-				image.Pt(self.Inner.Min.X+1, self.Inner.Min.Y+stage),
+				image.Pt(self.Inner.Min.X+1, self.Inner.Min.Y),
 			)
-			stage++
 		}
-		if self.LabelUpperLimit != "" {
-			buf.SetString(
-				self.LabelUpperLimit,
-				NewStyle(self.LimitColor),
-				image.Pt(self.Inner.Min.X+2, self.Inner.Min.Y+stage),
-			)
-			stage++
+		// legend of series and reference lines side by side
+		x := self.Inner.Min.X + 2
+		labels := []struct {
+			text  string
+			color Color
+		}{}
+		for _, series := range self.Series {
+			labels = append(labels, struct {
+				text  string
+				color Color
+			}{series.Label, series.Color})
 		}
-		if self.LabelData != "" {
-			buf.SetString(
-				self.LabelData,
-				NewStyle(self.DataColor),
-				image.Pt(self.Inner.Min.X+2, self.Inner.Min.Y+stage),
-			)
+		for _, line := range self.ReferenceLines {
+			label := line.Label
+			if label != "" && line.Value > max {
+				// the line is above the top
+				label += "↑"
+			}
+			labels = append(labels, struct {
+				text  string
+				color Color
+			}{label, line.Color})
+		}
+		for _, label := range labels {
+			if label.text == "" {
+				continue
+			}
+			if x >= self.Inner.Max.X {
+				break
+			}
+			text := TrimString(label.text, self.Inner.Max.X-x)
+			buf.SetString(text, NewStyle(label.color), image.Pt(x, self.Inner.Min.Y+1))
+			x += len([]rune(text)) + 2
+		}
+	}
+}
+
+// drawAxis describes the Y-axis with the ticks on the left of the plot,
+// and returns the width of it.
+func (self *Graph) drawAxis(buf *Buffer, plot image.Rectangle, max float64) int {
+	// a tick on every other row from the bottom, and the top
+	rows := plot.Dy()
+	ticks := make(map[int]string)
+	for row := 0; row < rows; row += 2 {
+		ticks[row] = self.tickLabel(row, rows, max)
+	}
+	ticks[rows-1] = self.tickLabel(rows-1, rows, max)
+
+	width := 0
+	for _, label := range ticks {
+		if len([]rune(label)) > width {
+			width = len([]rune(label))
 		}
 	}
+	width++
+	if width >= plot.Dx() {
+		return 0
+	}
+
+	style := NewStyle(self.AxisColor)
+	for row := 0; row < rows; row++ {
+		y := plot.Max.Y - 1 - row
+		label, ok := ticks[row]
+		if !ok {
+			buf.SetString("│", style, image.Pt(plot.Min.X+width-1, y))
+			continue
+		}
+		buf.SetString(
+			fmt.Sprintf("%*s┤", width-1, label),
+			style,
+			image.Pt(plot.Min.X, y),
+		)
+	}
+	return width
+}
+
+func (self *Graph) tickLabel(row, rows int, max float64) string {
+	if rows <= 1 {
+		return self.YTickFormatter(0)
+	}
+	return self.YTickFormatter(max * float64(row) / float64(rows-1))
+}
+
+// drawXAxis describes the time before the latest data under the plot.
+func (self *Graph) drawXAxis(buf *Buffer, plot image.Rectangle, n int) {
+	style := NewStyle(self.AxisColor)
+	y := plot.Max.Y
+	// from the latest data to the left
+	next := plot.Max.X
+	for i := n - 1; i >= 0; i-- {
+		label := "now"
+		if i < n-1 {
+			label = "-" + shortDuration(time.Duration(n-1-i)*self.Interval)
+		}
+		// align the label to the right of the column of the data
+		x := plot.Min.X + i
+		start := x - len(label) + 1
+		if start < plot.Min.X || x >= next {
+			continue
+		}
+		buf.SetString(label, style, image.Pt(start, y))
+		// keep some columns between labels
+		next = start - xAxisLabelGap
+	}
+}
+
+// shortDuration formats the duration without trailing zero units, e.g. 1m instead of 1m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
 }
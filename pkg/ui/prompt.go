@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"image"
+	"strings"
+
+	. "github.com/gizak/termui/v3"
+)
+
+type Prompt struct {
+	*Block
+
+	Label        string
+	Text         string
+	Message      string
+	LabelStyle   Style
+	TextStyle    Style
+	MessageStyle Style
+}
+
+func NewPrompt() *Prompt {
+	block := NewBlock()
+	block.Border = false
+	return &Prompt{
+		Block:        block,
+		LabelStyle:   NewStyle(Theme.Default.Fg, ColorClear, ModifierBold),
+		TextStyle:    NewStyle(Theme.Default.Fg),
+		MessageStyle: NewStyle(ColorRed),
+	}
+}
+
+func (self *Prompt) Insert(s string) {
+	self.Text += s
+}
+
+func (self *Prompt) Backspace() {
+	runes := []rune(self.Text)
+	if len(runes) > 0 {
+		self.Text = string(runes[:len(runes)-1])
+	}
+}
+
+func (self *Prompt) Draw(buf *Buffer) {
+	if self.Inner.Dy() < 1 {
+		return
+	}
+	// clear the line under the prompt
+	buf.SetString(
+		strings.Repeat(" ", self.Inner.Dx()),
+		self.TextStyle,
+		self.Inner.Min,
+	)
+
+	x := self.Inner.Min.X
+	for _, part := range []struct {
+		text  string
+		style Style
+	}{
+		{self.Label, self.LabelStyle},
+		{self.Text, self.TextStyle},
+		// cursor
+		{" ", NewStyle(self.TextStyle.Fg, ColorClear, ModifierReverse)},
+		{" " + self.Message, self.MessageStyle},
+	} {
+		if x >= self.Inner.Max.X {
+			break
+		}
+		text := TrimString(part.text, self.Inner.Max.X-x)
+		buf.SetString(text, part.style, image.Pt(x, self.Inner.Min.Y))
+		x += len([]rune(text))
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"math"
+	"strings"
+)
+
+var (
+	sparkBars = []rune("▁▂▃▄▅▆▇█")
+)
+
+// Sparkline draws the latest data up to the width with bars from zero to the peak of the data,
+// padded on the left to the width. NaN is a gap of the data drawn as a space.
+func Sparkline(data []float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(data) > width {
+		data = data[len(data)-width:]
+	}
+	peak := 0.
+	for _, v := range data {
+		if v > peak {
+			peak = v
+		}
+	}
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(data)))
+	for _, v := range data {
+		if math.IsNaN(v) {
+			b.WriteRune(' ')
+			continue
+		}
+		level := 0
+		if peak > 0 && v > 0 {
+			level = int(v / peak * float64(len(sparkBars)-1))
+		}
+		b.WriteRune(sparkBars[level])
+	}
+	return b.String()
+}
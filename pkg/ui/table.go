@@ -7,15 +7,33 @@ import (
 	. "github.com/gizak/termui/v3"
 )
 
+const (
+	tableMarker = "●"
+)
+
 type Table struct {
 	*Block
 
 	Header       []string
 	ColumnWidths []int
 	Rows         [][]string
-	Cursor       bool
-	CursorColor  Color
-	topRow       int
+	// colors for each row, ColorClear to use the default
+	RowColors []Color
+	// colors for each cell over the color of the row, ColorClear to use the row color
+	CellColors [][]Color
+	// colors of the markers at the end of each row, ColorClear for no marker
+	MarkColors []Color
+	// trend of each row drawn as a sparkline at the end of the row, hidden if the width is 0
+	SparklineHeader string
+	Sparklines      [][]float64
+	SparklineWidth  int
+	Cursor          bool
+	CursorColor     Color
+	topRow          int
+
+	// column marked as sorted, or -1 for none
+	SortColumn     int
+	SortDescending bool
 
 	SelectedRow int
 }
@@ -26,6 +44,7 @@ func NewTable() *Table {
 		Cursor:      true,
 		topRow:      0,
 		SelectedRow: 0,
+		SortColumn:  -1,
 	}
 }
 
@@ -34,10 +53,24 @@ func (self *Table) Reset(title string, header []string, width []int) {
 	self.Header = header
 	self.ColumnWidths = width
 	self.Rows = [][]string{}
+	self.RowColors = nil
+	self.CellColors = nil
+	self.MarkColors = nil
+	self.Sparklines = nil
 	self.topRow = 0
 	self.SelectedRow = 0
 }
 
+// ColumnsRect returns the area for the columns, which excludes the sparklines.
+func (self *Table) ColumnsRect() image.Rectangle {
+	rect := self.Inner
+	if self.SparklineWidth > 0 {
+		// a space before and the marker after the sparkline
+		rect.Max.X -= self.SparklineWidth + 2
+	}
+	return rect
+}
+
 func (self *Table) Draw(buf *Buffer) {
 	self.Block.Draw(buf)
 
@@ -52,12 +85,27 @@ func (self *Table) Draw(buf *Buffer) {
 
 		// describe a header
 		for i, h := range self.Header {
+			if i == self.SortColumn {
+				if self.SortDescending {
+					h += "▼"
+				} else {
+					h += "▲"
+				}
+			}
 			buf.SetString(
 				h,
 				NewStyle(Theme.Default.Fg, ColorClear, ModifierBold),
 				image.Pt(self.Inner.Min.X+columnPositions[i], self.Inner.Min.Y),
 			)
 		}
+		sparklineX := self.Inner.Max.X - self.SparklineWidth - 1
+		if self.SparklineWidth > 0 {
+			buf.SetString(
+				TrimString(self.SparklineHeader, self.SparklineWidth),
+				NewStyle(Theme.Default.Fg, ColorClear, ModifierBold),
+				image.Pt(sparklineX, self.Inner.Min.Y),
+			)
+		}
 
 		if self.SelectedRow < self.topRow {
 			self.topRow = self.SelectedRow
@@ -71,6 +119,9 @@ func (self *Table) Draw(buf *Buffer) {
 			// move y+1 for a header
 			y := self.Inner.Min.Y + 1 + idx - self.topRow
 			style := NewStyle(Theme.Default.Fg)
+			if idx < len(self.RowColors) && self.RowColors[idx] != ColorClear {
+				style.Fg = self.RowColors[idx]
+			}
 			if self.Cursor {
 				if idx == self.SelectedRow {
 					style.Fg = self.CursorColor
@@ -85,12 +136,31 @@ func (self *Table) Draw(buf *Buffer) {
 			}
 			for i, width := range self.ColumnWidths {
 				r := TrimString(row[i], width)
+				cellStyle := style
+				if idx < len(self.CellColors) && i < len(self.CellColors[idx]) && self.CellColors[idx][i] != ColorClear {
+					cellStyle.Fg = self.CellColors[idx][i]
+					cellStyle.Modifier |= ModifierBold
+				}
 				buf.SetString(
 					r,
-					style,
+					cellStyle,
 					image.Pt(self.Inner.Min.X+columnPositions[i], y),
 				)
 			}
+			if self.SparklineWidth > 0 && idx < len(self.Sparklines) {
+				buf.SetString(
+					Sparkline(self.Sparklines[idx], self.SparklineWidth),
+					style,
+					image.Pt(sparklineX, y),
+				)
+			}
+			if idx < len(self.MarkColors) && self.MarkColors[idx] != ColorClear {
+				buf.SetString(
+					tableMarker,
+					NewStyle(self.MarkColors[idx], ColorClear, style.Modifier),
+					image.Pt(self.Inner.Max.X-1, y),
+				)
+			}
 		}
 	}
 }
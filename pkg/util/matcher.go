@@ -0,0 +1,82 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+type MatchMode int
+
+const (
+	RegexpMatch MatchMode = iota
+	SubstringMatch
+	FuzzyMatch
+)
+
+func (m MatchMode) String() string {
+	switch m {
+	case SubstringMatch:
+		return "substring"
+	case FuzzyMatch:
+		return "fuzzy"
+	default:
+		return "regexp"
+	}
+}
+
+// Next returns the mode to switch to from the mode.
+func (m MatchMode) Next() MatchMode {
+	return (m + 1) % (FuzzyMatch + 1)
+}
+
+// Matcher reports whether names match a query.
+type Matcher interface {
+	MatchString(s string) bool
+	String() string
+}
+
+func NewMatcher(mode MatchMode, query string) (Matcher, error) {
+	switch mode {
+	case SubstringMatch:
+		return substringMatcher(query), nil
+	case FuzzyMatch:
+		return fuzzyMatcher(query), nil
+	default:
+		return regexp.Compile(query)
+	}
+}
+
+type substringMatcher string
+
+func (m substringMatcher) MatchString(s string) bool {
+	return strings.Contains(s, string(m))
+}
+
+func (m substringMatcher) String() string {
+	return string(m)
+}
+
+// fuzzyMatcher matches names which contain all characters of the query in order,
+// ignoring case.
+type fuzzyMatcher string
+
+func (m fuzzyMatcher) MatchString(s string) bool {
+	query := []rune(strings.ToLower(string(m)))
+	if len(query) == 0 {
+		return true
+	}
+	i := 0
+	for _, r := range strings.ToLower(s) {
+		if r == query[i] {
+			i++
+			if i == len(query) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m fuzzyMatcher) String() string {
+	return string(m)
+}
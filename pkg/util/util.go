@@ -2,43 +2,12 @@ package util
 
 import (
 	"fmt"
-	"regexp"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/metrics/pkg/apis/metrics"
 )
 
-func FilterNodeMetrics(query *regexp.Regexp, nodes []metrics.NodeMetrics) []metrics.NodeMetrics {
-	var filtered []metrics.NodeMetrics
-	for _, node := range nodes {
-		if query.MatchString(node.Name) {
-			filtered = append(filtered, node)
-		}
-	}
-	return filtered
-}
-
-func FilterPodMetrics(query *regexp.Regexp, pods []metrics.PodMetrics) []metrics.PodMetrics {
-	var filtered []metrics.PodMetrics
-	for _, pod := range pods {
-		if query.MatchString(pod.Name) {
-			filtered = append(filtered, pod)
-		}
-	}
-	return filtered
-}
-
-func FilterContainerMetrics(query *regexp.Regexp, containers []metrics.ContainerMetrics) []metrics.ContainerMetrics {
-	var filtered []metrics.ContainerMetrics
-	for _, container := range containers {
-		if query.MatchString(container.Name) {
-			filtered = append(filtered, container)
-		}
-	}
-	return filtered
-}
-
 func FindNode(name string, nodes []corev1.Node) *corev1.Node {
 	for _, node := range nodes {
 		if name == node.Name {
@@ -48,16 +17,7 @@ func FindNode(name string, nodes []corev1.Node) *corev1.Node {
 	return nil
 }
 
-func FindPod(name string, pods []corev1.Pod) *corev1.Pod {
-	for _, pod := range pods {
-		if name == pod.Name {
-			return &pod
-		}
-	}
-	return nil
-}
-
-func FindContainer(name string, containers []corev1.Container) *corev1.Container {
+func FindContainerMetrics(name string, containers []metrics.ContainerMetrics) *metrics.ContainerMetrics {
 	for _, container := range containers {
 		if name == container.Name {
 			return &container
@@ -110,3 +70,15 @@ func IntMin(x, y int) int {
 	}
 	return y
 }
+
+// GetRawResourceValue returns millicores for cpu and bytes for memory.
+func GetRawResourceValue(lst corev1.ResourceList, typ corev1.ResourceName) (int64, bool) {
+	val, ok := lst[typ]
+	switch {
+	case typ == corev1.ResourceCPU && ok:
+		return val.MilliValue(), true
+	case ok:
+		return val.Value(), true
+	}
+	return 0, false
+}